@@ -4,7 +4,7 @@ bitsadmin.exe /transfer "DownloadStableCLI" https://s3.amazonaws.com/go-cli/buil
 go get -u code.cloudfoundry.org/cli-acceptance-tests/...
 
 SET GATSPATH=%GOPATH%\src\github.com\cloudfoundry\cli-acceptance-tests
-SET PATH=%GATSPATH%;%PATH%;C:\Program Files\cURL\bin
+SET PATH=%GATSPATH%;%PATH%
 SET CONFIG=%CD%\gats_config.json
 
 go install -v github.com/onsi/ginkgo/ginkgo
@@ -0,0 +1,96 @@
+package ab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+const (
+	// SideEnvVar selects which of the configured cf binaries a run uses.
+	SideEnvVar = gatsHelpers.ABSideEnvVar
+
+	SideA = "a"
+	SideB = "b"
+)
+
+// Command is one cf invocation made by a spec, with secrets redacted.
+type Command struct {
+	Args     []string      `json:"args"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SpecRun is every cf invocation a spec made, in order.
+type SpecRun struct {
+	Spec     string    `json:"spec"`
+	Commands []Command `json:"commands"`
+}
+
+// ResultsDirectory is where a suite's A/B results are written.
+func ResultsDirectory(artifactsDirectory, suiteName string) string {
+	return filepath.Join(artifactsDirectory, "ab", suiteName)
+}
+
+// WriteRuns saves the runs of one side on one parallel node.
+func WriteRuns(directory, side string, node int, runs []SpecRun) error {
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(directory, fmt.Sprintf("%s-%d.json", side, node)), contents, 0644)
+}
+
+// LoadRuns reads the runs of one side from every parallel node, sorted by spec.
+func LoadRuns(directory, side string) ([]SpecRun, error) {
+	paths, err := filepath.Glob(filepath.Join(directory, side+"-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no results for side %s in %s", side, directory)
+	}
+
+	var runs []SpecRun
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var nodeRuns []SpecRun
+		err = json.Unmarshal(contents, &nodeRuns)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", path, err)
+		}
+		runs = append(runs, nodeRuns...)
+	}
+
+	sort.Sort(bySpec(runs))
+	return runs, nil
+}
+
+func (c Command) String() string {
+	return "cf " + strings.Join(c.Args, " ")
+}
+
+type bySpec []SpecRun
+
+func (s bySpec) Len() int           { return len(s) }
+func (s bySpec) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySpec) Less(i, j int) bool { return s[i].Spec < s[j].Spec }
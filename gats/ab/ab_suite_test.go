@@ -0,0 +1,14 @@
+package ab_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAb(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "A/B Suite")
+}
@@ -0,0 +1,163 @@
+package ab
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	. "github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+// unfinishedGrace bounds how long AfterEach waits for a command the spec
+// left running (e.g. `cf logs`) before recording it as unfinished.
+const unfinishedGrace = 5 * time.Second
+
+// RegisterHooks runs the calling suite with the cf binary selected by
+// GATS_AB_SIDE and records every cf invocation of every spec. Call it from a
+// top-level `var _ =`, like cassette.RegisterHooks.
+func RegisterHooks(suiteName string) bool {
+	side := os.Getenv(SideEnvVar)
+	if side != SideA && side != SideB {
+		return false
+	}
+
+	var binary string
+	var binaryOnce sync.Once
+	resolveBinary := func() string {
+		binaryOnce.Do(func() {
+			binary = gatsHelpers.LoadConfig().CfBinary()
+		})
+		return binary
+	}
+
+	originalInterceptor := runner.CommandInterceptor
+	runner.CommandInterceptor = func(cmd *exec.Cmd) *exec.Cmd {
+		name := filepath.Base(cmd.Path)
+		if name == "cf" || name == "cf.exe" {
+			cmd = withBinary(cmd, resolveBinary())
+		}
+		return originalInterceptor(cmd)
+	}
+
+	recorder := &recorder{}
+
+	BeforeEach(func() {
+		Expect(resolveBinary()).NotTo(BeEmpty(), "%s=%s needs cf_binary_a and cf_binary_b in the config", SideEnvVar, side)
+		recorder.install()
+		recorder.start(CurrentGinkgoTestDescription().FullTestText)
+	})
+
+	AfterEach(func() {
+		recorder.finish()
+		directory := ResultsDirectory(gatsHelpers.LoadConfig().ArtifactsDirectory, suiteName)
+		Expect(WriteRuns(directory, side, ginkgoconfig.GinkgoConfig.ParallelNode, recorder.runs)).To(Succeed())
+	})
+
+	return true
+}
+
+// withBinary copies cmd to run binary instead. A fresh exec.Command resolves
+// binary itself, so the copy doesn't carry the error cmd got when there is no
+// cf on PATH.
+func withBinary(cmd *exec.Cmd, binary string) *exec.Cmd {
+	replacement := exec.Command(binary, cmd.Args[1:]...)
+	replacement.Env = cmd.Env
+	replacement.Dir = cmd.Dir
+	replacement.Stdin = cmd.Stdin
+	replacement.Stdout = cmd.Stdout
+	replacement.Stderr = cmd.Stderr
+	replacement.ExtraFiles = cmd.ExtraFiles
+	replacement.SysProcAttr = cmd.SysProcAttr
+	return replacement
+}
+
+type pendingCommand struct {
+	command  *Command
+	session  *gexec.Session
+	started  time.Time
+	finished chan time.Time
+}
+
+type recorder struct {
+	installed bool
+
+	mutex   sync.Mutex
+	current *SpecRun
+	pending []pendingCommand
+	runs    []SpecRun
+}
+
+// install wraps cf.Cf. It runs from the first BeforeEach so that it wraps
+// whatever the suite installed (e.g. redaction) before RunSpecs.
+func (r *recorder) install() {
+	if r.installed {
+		return
+	}
+	r.installed = true
+
+	original := cf.Cf
+	cf.Cf = func(args ...string) *gexec.Session {
+		started := time.Now()
+		session := original(args...)
+
+		finished := make(chan time.Time, 1)
+		go func() {
+			<-session.Exited
+			finished <- time.Now()
+		}()
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if r.current != nil {
+			r.pending = append(r.pending, pendingCommand{
+				command:  &Command{Args: gatsHelpers.RedactArgs(args)},
+				session:  session,
+				started:  started,
+				finished: finished,
+			})
+		}
+		return session
+	}
+}
+
+func (r *recorder) start(spec string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.current = &SpecRun{Spec: spec}
+	r.pending = nil
+}
+
+func (r *recorder) finish() {
+	r.mutex.Lock()
+	pending := r.pending
+	run := r.current
+	r.current = nil
+	r.pending = nil
+	r.mutex.Unlock()
+
+	for _, p := range pending {
+		select {
+		case exited := <-p.finished:
+			p.command.ExitCode = p.session.ExitCode()
+			p.command.Duration = exited.Sub(p.started)
+		case <-time.After(unfinishedGrace):
+			p.command.ExitCode = -1
+			p.command.Duration = time.Since(p.started)
+		}
+
+		p.command.Stdout = gatsHelpers.RedactText(string(p.session.Out.Contents()))
+		p.command.Stderr = gatsHelpers.RedactText(string(p.session.Err.Contents()))
+		run.Commands = append(run.Commands, *p.command)
+	}
+
+	r.runs = append(r.runs, *run)
+}
@@ -0,0 +1,211 @@
+package ab
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+// CommandDiff compares the nth cf invocation of a spec between the two sides.
+// Either side is nil when one binary made more invocations than the other.
+type CommandDiff struct {
+	A *Command
+	B *Command
+
+	// ArgsA and ArgsB are the normalized command lines, so random names
+	// and time tags in them don't count as a difference.
+	ArgsA string
+	ArgsB string
+
+	// StdoutDiff and StderrDiff are line diffs of the normalized output,
+	// empty when it matches.
+	StdoutDiff []string
+	StderrDiff []string
+}
+
+func (d CommandDiff) Differs() bool {
+	return d.A == nil || d.B == nil ||
+		d.ArgsA != d.ArgsB ||
+		d.A.ExitCode != d.B.ExitCode ||
+		len(d.StdoutDiff) > 0 || len(d.StderrDiff) > 0
+}
+
+type SpecDiff struct {
+	Spec     string
+	MissingA bool
+	MissingB bool
+	Commands []CommandDiff
+}
+
+func (d SpecDiff) Differs() bool {
+	if d.MissingA || d.MissingB {
+		return true
+	}
+	for _, command := range d.Commands {
+		if command.Differs() {
+			return true
+		}
+	}
+	return false
+}
+
+type Report struct {
+	Specs []SpecDiff
+}
+
+// Compare pairs up the specs and commands of both sides and diffs their
+// normalized output. Each spec gets its own normalizer per side, so GUIDs
+// line up by order of first appearance rather than by value.
+func Compare(a, b []SpecRun) Report {
+	runsA := map[string]SpecRun{}
+	for _, run := range a {
+		runsA[run.Spec] = run
+	}
+	runsB := map[string]SpecRun{}
+	for _, run := range b {
+		runsB[run.Spec] = run
+	}
+
+	var specs []string
+	seen := map[string]bool{}
+	for _, run := range append(append([]SpecRun{}, a...), b...) {
+		if !seen[run.Spec] {
+			seen[run.Spec] = true
+			specs = append(specs, run.Spec)
+		}
+	}
+
+	var report Report
+	for _, spec := range specs {
+		runA, okA := runsA[spec]
+		runB, okB := runsB[spec]
+		diff := SpecDiff{Spec: spec, MissingA: !okA, MissingB: !okB}
+
+		normalizerA, normalizerB := gatsHelpers.NewOutputNormalizer(), gatsHelpers.NewOutputNormalizer()
+		for i := 0; i < len(runA.Commands) || i < len(runB.Commands); i++ {
+			var commandDiff CommandDiff
+			var stdoutA, stdoutB, stderrA, stderrB string
+
+			if i < len(runA.Commands) {
+				commandDiff.A = &runA.Commands[i]
+				commandDiff.ArgsA = normalizerA.Normalize(strings.Join(runA.Commands[i].Args, " "))
+				stdoutA = normalizerA.Normalize(runA.Commands[i].Stdout)
+				stderrA = normalizerA.Normalize(runA.Commands[i].Stderr)
+			}
+			if i < len(runB.Commands) {
+				commandDiff.B = &runB.Commands[i]
+				commandDiff.ArgsB = normalizerB.Normalize(strings.Join(runB.Commands[i].Args, " "))
+				stdoutB = normalizerB.Normalize(runB.Commands[i].Stdout)
+				stderrB = normalizerB.Normalize(runB.Commands[i].Stderr)
+			}
+
+			commandDiff.StdoutDiff = gatsHelpers.LineDiff(stdoutA, stdoutB)
+			commandDiff.StderrDiff = gatsHelpers.LineDiff(stderrA, stderrB)
+			diff.Commands = append(diff.Commands, commandDiff)
+		}
+
+		report.Specs = append(report.Specs, diff)
+	}
+
+	return report
+}
+
+func (r Report) Differs() bool {
+	for _, spec := range r.Specs {
+		if spec.Differs() {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders every spec with differences, followed by the per-command
+// durations of all specs.
+func (r Report) String() string {
+	buffer := &bytes.Buffer{}
+
+	differing := 0
+	for _, spec := range r.Specs {
+		if !spec.Differs() {
+			continue
+		}
+		differing++
+
+		fmt.Fprintf(buffer, "=== %s\n", spec.Spec)
+		switch {
+		case spec.MissingA:
+			fmt.Fprintln(buffer, "  only ran with B")
+			continue
+		case spec.MissingB:
+			fmt.Fprintln(buffer, "  only ran with A")
+			continue
+		}
+
+		for _, command := range spec.Commands {
+			if !command.Differs() {
+				continue
+			}
+
+			switch {
+			case command.A == nil:
+				fmt.Fprintf(buffer, "  only B ran: %s\n", command.B)
+				continue
+			case command.B == nil:
+				fmt.Fprintf(buffer, "  only A ran: %s\n", command.A)
+				continue
+			}
+
+			if command.ArgsA != command.ArgsB {
+				fmt.Fprintf(buffer, "  A ran: %s\n  B ran: %s\n", command.A, command.B)
+			} else {
+				fmt.Fprintf(buffer, "  %s\n", command.A)
+			}
+			if command.A.ExitCode != command.B.ExitCode {
+				fmt.Fprintf(buffer, "    exit code: A %d, B %d\n", command.A.ExitCode, command.B.ExitCode)
+			}
+			writeLineDiff(buffer, "stdout", command.StdoutDiff)
+			writeLineDiff(buffer, "stderr", command.StderrDiff)
+		}
+	}
+
+	fmt.Fprintf(buffer, "%d of %d specs differ\n", differing, len(r.Specs))
+
+	fmt.Fprintln(buffer, "\ndurations (A / B):")
+	for _, spec := range r.Specs {
+		fmt.Fprintf(buffer, "  %s\n", spec.Spec)
+		for _, command := range spec.Commands {
+			name := ""
+			var durationA, durationB time.Duration
+			if command.A != nil {
+				name, durationA = command.A.String(), command.A.Duration
+			}
+			if command.B != nil {
+				name, durationB = command.B.String(), command.B.Duration
+			}
+			fmt.Fprintf(buffer, "    %-10s %-10s %s\n", round(durationA), round(durationB), name)
+		}
+	}
+
+	return buffer.String()
+}
+
+func writeLineDiff(buffer *bytes.Buffer, name string, diff []string) {
+	if len(diff) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buffer, "    %s:\n", name)
+	for _, line := range diff {
+		fmt.Fprintf(buffer, "      %s\n", line)
+	}
+}
+
+func round(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return (d / time.Millisecond * time.Millisecond).String()
+}
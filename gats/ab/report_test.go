@@ -0,0 +1,104 @@
+package ab_test
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/ab"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compare", func() {
+	It("ignores GUIDs, timestamps and the version banner", func() {
+		a := []SpecRun{{Spec: "pushes an app", Commands: []Command{
+			{Args: []string{"version"}, Stdout: "cf version 6.21.1+6fd3c9f-2016-08-10\n"},
+			{Args: []string{"app", "dora", "--guid"}, Stdout: "0a1b2c3d-1111-2222-3333-444455556666\n", Duration: time.Second},
+			{Args: []string{"events", "dora"}, Stdout: "2016-10-27T10:00:00.00-0700   audit.app.create   admin\n"},
+		}}}
+		b := []SpecRun{{Spec: "pushes an app", Commands: []Command{
+			{Args: []string{"version"}, Stdout: "cf version 6.22.2+a95e24c-2016-10-27\n"},
+			{Args: []string{"app", "dora", "--guid"}, Stdout: "99999999-aaaa-bbbb-cccc-ddddeeeeffff\n", Duration: 2 * time.Second},
+			{Args: []string{"events", "dora"}, Stdout: "2016-10-28T11:30:00.00-0700   audit.app.create   admin\n"},
+		}}}
+
+		report := Compare(a, b)
+		Expect(report.Differs()).To(BeFalse())
+		Expect(report.String()).To(ContainSubstring("0 of 1 specs differ"))
+		Expect(report.String()).To(ContainSubstring("1s         2s         cf app dora --guid"))
+	})
+
+	It("reports changed output and exit codes per command", func() {
+		a := []SpecRun{{Spec: "deletes an org", Commands: []Command{
+			{Args: []string{"delete-org", "-f", "nope"}, Stdout: "Deleting org nope...\nOK\n\nOrg nope does not exist.\n", ExitCode: 0},
+		}}}
+		b := []SpecRun{{Spec: "deletes an org", Commands: []Command{
+			{Args: []string{"delete-org", "-f", "nope"}, Stdout: "Deleting org nope...\nOrg nope does not exist.\nOK\n", ExitCode: 1},
+		}}}
+
+		report := Compare(a, b)
+		Expect(report.Differs()).To(BeTrue())
+		Expect(report.String()).To(ContainSubstring("=== deletes an org\n  cf delete-org -f nope\n    exit code: A 0, B 1\n    stdout:\n"))
+		Expect(report.Specs[0].Commands[0].StdoutDiff).To(Equal([]string{"- OK", "- ", "+ OK"}))
+	})
+
+	It("ignores random names in the command lines", func() {
+		a := []SpecRun{{Spec: "pushes an app", Commands: []Command{
+			{Args: []string{"push", "GATS-APP-0a1b2c3d-1111-2222-3333-444455556666", "-p", "dora"}, Stdout: "Creating app GATS-APP-0a1b2c3d-1111-2222-3333-444455556666...\n"},
+			{Args: []string{"create-service-key", "db", "key-2016_10_27-10h00m00.123s"}},
+		}}}
+		b := []SpecRun{{Spec: "pushes an app", Commands: []Command{
+			{Args: []string{"push", "GATS-APP-99999999-aaaa-bbbb-cccc-ddddeeeeffff", "-p", "dora"}, Stdout: "Creating app GATS-APP-99999999-aaaa-bbbb-cccc-ddddeeeeffff...\n"},
+			{Args: []string{"create-service-key", "db", "key-2016_10_28-11h30m00.456s"}},
+		}}}
+
+		report := Compare(a, b)
+		Expect(report.Differs()).To(BeFalse())
+		Expect(report.Specs[0].Commands[0].ArgsA).To(Equal("push GATS-APP-00000000-0000-0000-0000-000000000001 -p dora"))
+	})
+
+	It("reports both command lines when the normalized arguments differ", func() {
+		a := []SpecRun{{Spec: "scales an app", Commands: []Command{{Args: []string{"scale", "dora", "-i", "2"}}}}}
+		b := []SpecRun{{Spec: "scales an app", Commands: []Command{{Args: []string{"scale", "dora", "-i", "3"}}}}}
+
+		report := Compare(a, b)
+		Expect(report.Differs()).To(BeTrue())
+		Expect(report.String()).To(ContainSubstring("  A ran: cf scale dora -i 2\n  B ran: cf scale dora -i 3\n"))
+	})
+
+	It("reports specs and commands that only ran on one side", func() {
+		a := []SpecRun{
+			{Spec: "both", Commands: []Command{{Args: []string{"target"}}, {Args: []string{"apps"}}}},
+			{Spec: "only a"},
+		}
+		b := []SpecRun{
+			{Spec: "both", Commands: []Command{{Args: []string{"target"}}}},
+		}
+
+		output := Compare(a, b).String()
+		Expect(output).To(ContainSubstring("  only A ran: cf apps\n"))
+		Expect(output).To(ContainSubstring("=== only a\n  only ran with A\n"))
+	})
+})
+
+var _ = Describe("Runs", func() {
+	It("round-trips the runs of every node of a side", func() {
+		directory, err := ioutil.TempDir("", "gats-ab")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(directory)
+
+		Expect(WriteRuns(directory, SideA, 2, []SpecRun{{Spec: "z"}})).To(Succeed())
+		Expect(WriteRuns(directory, SideA, 1, []SpecRun{{Spec: "y", Commands: []Command{{Args: []string{"apps"}, ExitCode: 1}}}})).To(Succeed())
+
+		runs, err := LoadRuns(directory, SideA)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs).To(HaveLen(2))
+		Expect(runs[0].Spec).To(Equal("y"))
+		Expect(runs[0].Commands[0].ExitCode).To(Equal(1))
+
+		_, err = LoadRuns(directory, SideB)
+		Expect(err).To(HaveOccurred())
+	})
+})
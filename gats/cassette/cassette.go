@@ -0,0 +1,77 @@
+package cassette
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// StandInOrigin replaces the scheme and host of every endpoint the CLI talked
+// to while recording. The player swaps it for its own address.
+const StandInOrigin = "{{stand-in}}"
+
+type Request struct {
+	Method string `json:"method"`
+	Host   string `json:"host"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       string              `json:"body,omitempty"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Cassette struct {
+	Name         string        `json:"name"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+func Load(path string) (Cassette, error) {
+	var c Cassette
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+
+	err = json.Unmarshal(contents, &c)
+	return c, err
+}
+
+func (c Cassette) Save(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+// FileName derives a stable cassette file name from a spec's full text.
+func FileName(specText string) string {
+	name := strings.Trim(unsafeFileNameCharacters.ReplaceAllString(specText, "_"), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+
+	return fmt.Sprintf("%s-%x.json", name, sha1.Sum([]byte(specText)))
+}
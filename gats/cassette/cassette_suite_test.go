@@ -0,0 +1,14 @@
+package cassette_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCassette(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Cassette Suite")
+}
@@ -0,0 +1,97 @@
+package cassette_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/cassette"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const trace = "\n\x1b[35mREQUEST:\x1b[0m [2016-10-19T10:00:00Z]\r\n" +
+	"GET /v2/info HTTP/1.1\r\nHost: api.bosh-lite.com\r\nAccept: application/json\r\n\r\n\n\n" +
+	"\x1b[35mRESPONSE:\x1b[0m [2016-10-19T10:00:01Z]\r\n" +
+	"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nDate: Wed, 19 Oct 2016 10:00:01 GMT\r\n\r\n" +
+	`{"authorization_endpoint":"https://login.bosh-lite.com","logging_endpoint":"wss://loggregator.bosh-lite.com:443"}` + "\n\n" +
+	"REQUEST: [2016-10-19T10:00:02Z]\n" +
+	"POST /v2/organizations HTTP/1.1\r\nHost: api.bosh-lite.com\r\n\r\n" +
+	`{"name":"CATS-ORG-1-2016_10_19-10h00m02.123s"}` + "\n\n" +
+	"RESPONSE: [2016-10-19T10:00:03Z]\n" +
+	"HTTP/1.1 201 Created\r\nContent-Type: application/json\r\n\r\n" +
+	`{"metadata":{"guid":"8A2F0E5C-1234-4C4B-9D7E-0123456789AB","created_at":"2016-10-19T10:00:03Z"},"entity":{"name":"CATS-ORG-1-2016_10_19-10h00m02.123s"}}` + "\n\n" +
+	"REQUEST: [2016-10-19T10:00:04Z]\n" +
+	"GET /v2/organizations/8a2f0e5c-1234-4c4b-9d7e-0123456789ab HTTP/1.1\r\nHost: api.bosh-lite.com\r\n\r\n\n\n" +
+	"RESPONSE: [2016-10-19T10:00:05Z]\n" +
+	"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" +
+	`{"metadata":{"guid":"8a2f0e5c-1234-4c4b-9d7e-0123456789ab"},"entity":{"name":"CATS-ORG-1-2016_10_19-10h00m02.123s"}}` + "\n"
+
+var _ = Describe("Cassettes", func() {
+	var interactions []Interaction
+
+	BeforeEach(func() {
+		interactions = NewNormalizer().Normalize(ParseTrace(trace))
+	})
+
+	It("pairs dumped requests with their responses", func() {
+		Expect(interactions).To(HaveLen(3))
+		Expect(interactions[0].Request.Method).To(Equal("GET"))
+		Expect(interactions[0].Request.Path).To(Equal("/v2/info"))
+		Expect(interactions[1].Response.StatusCode).To(Equal(201))
+	})
+
+	It("normalizes origins, guids, timestamps and time tags", func() {
+		Expect(interactions[0].Response.Body).To(Equal(`{"authorization_endpoint":"{{stand-in}}","logging_endpoint":"{{stand-in}}"}`))
+		Expect(interactions[0].Response.Header).To(Equal(map[string][]string{"Content-Type": {"application/json"}}))
+		Expect(interactions[1].Request.Body).To(Equal(`{"name":"CATS-ORG-1-2000_01_01-00h00m00s"}`))
+		Expect(interactions[1].Response.Body).To(ContainSubstring(`"guid":"00000000-0000-0000-0000-000000000001","created_at":"2000-01-01T00:00:00Z"`))
+		Expect(interactions[2].Request.Path).To(Equal("/v2/organizations/00000000-0000-0000-0000-000000000001"))
+	})
+
+	It("produces the same cassette for the same trace", func() {
+		Expect(NewNormalizer().Normalize(ParseTrace(trace))).To(Equal(interactions))
+	})
+
+	Describe("Player", func() {
+		var player *Player
+
+		BeforeEach(func() {
+			player = NewPlayer()
+			player.Load(Cassette{Interactions: interactions})
+		})
+
+		AfterEach(func() {
+			player.Close()
+		})
+
+		get := func(path string) string {
+			response, err := http.Get(player.URL() + path)
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+
+			body, err := ioutil.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return string(body)
+		}
+
+		It("serves recorded responses with the stand-in as origin", func() {
+			Expect(get("/v2/info")).To(ContainSubstring(`"authorization_endpoint":"` + player.URL() + `"`))
+			Expect(player.Misses()).To(BeEmpty())
+		})
+
+		It("binds placeholders to the values sent during replay", func() {
+			response, err := http.Post(player.URL()+"/v2/organizations", "application/json", strings.NewReader(`{"name":"CATS-ORG-1-2016_12_01-09h30m00.5s"}`))
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			Expect(get("/v2/organizations/00000000-0000-0000-0000-000000000001")).To(ContainSubstring("CATS-ORG-1-2016_12_01-09h30m00.5s"))
+		})
+
+		It("records requests it has no interaction for", func() {
+			get("/v2/apps")
+			Expect(player.Misses()).To(ConsistOf("GET /v2/apps"))
+		})
+	})
+})
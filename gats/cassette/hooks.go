@@ -0,0 +1,89 @@
+package cassette
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	ModeEnvVar      = "GATS_HTTP_MODE"
+	DirectoryEnvVar = "GATS_CASSETTE_DIR"
+
+	RecordMode = "record"
+	ReplayMode = "replay"
+)
+
+// Directory is where the cassettes of a suite live: $GATS_CASSETTE_DIR/<suite>,
+// or gats/cassettes/<suite> when run from a suite directory.
+func Directory(suiteName string) string {
+	root := os.Getenv(DirectoryEnvVar)
+	if root == "" {
+		root = filepath.Join("..", "cassettes")
+	}
+
+	return filepath.Join(root, suiteName)
+}
+
+// Replaying reports whether GATS_HTTP_MODE serves cf from cassettes instead
+// of a foundation.
+func Replaying() bool {
+	return os.Getenv(ModeEnvVar) == ReplayMode
+}
+
+// RegisterHooks records or replays every spec of the calling suite according
+// to GATS_HTTP_MODE. Call it from a top-level `var _ =` so the hooks run
+// before the suite's own BeforeEach blocks.
+func RegisterHooks(suiteName string) bool {
+	switch os.Getenv(ModeEnvVar) {
+	case RecordMode:
+		registerRecordHooks(Directory(suiteName))
+	case ReplayMode:
+		registerReplayHooks(Directory(suiteName))
+	}
+
+	return true
+}
+
+func registerRecordHooks(directory string) {
+	recorder := NewRecorder(directory)
+
+	BeforeEach(func() {
+		Expect(recorder.Start(CurrentGinkgoTestDescription().FullTestText)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		_, err := recorder.Stop()
+		Expect(err).NotTo(HaveOccurred())
+	})
+}
+
+func registerReplayHooks(directory string) {
+	var player *Player
+	originalInterceptor := runner.CommandInterceptor
+
+	BeforeEach(func() {
+		if player == nil {
+			player = NewPlayer()
+			runner.CommandInterceptor = func(cmd *exec.Cmd) *exec.Cmd {
+				return originalInterceptor(RetargetCommand(cmd, player.URL()))
+			}
+		}
+
+		c, err := Load(filepath.Join(directory, FileName(CurrentGinkgoTestDescription().FullTestText)))
+		Expect(err).NotTo(HaveOccurred(), "no cassette recorded for this spec; run with %s=%s first", ModeEnvVar, RecordMode)
+		player.Load(c)
+
+		if cfHome := os.Getenv("CF_HOME"); cfHome != "" {
+			Expect(RetargetCfHome(cfHome, player.URL())).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(player.Misses()).To(BeEmpty(), "requests without a recorded interaction")
+	})
+}
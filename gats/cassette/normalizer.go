@@ -0,0 +1,152 @@
+package cassette
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+var (
+	guidPattern      = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})`)
+	timeTagPattern   = regexp.MustCompile(`\d{4}_\d{2}_\d{2}-\d{2}h\d{2}m\d{2}(\.\d+)?s`)
+	tokenPattern     = regexp.MustCompile(`"(access_token|refresh_token)"\s*:\s*"[^"]*"`)
+)
+
+const (
+	normalizedTimestamp = "2000-01-01T00:00:00Z"
+	normalizedTimeTag   = "2000_01_01-00h00m00s"
+	replayUserName      = "gats-replay-user"
+	replayRefreshToken  = "gats-replay-refresh-token"
+)
+
+// keptResponseHeaders are the only response headers the CLI acts on; all
+// others (dates, request ids, cookies) would make cassettes differ per run.
+var keptResponseHeaders = []string{"Content-Type", "Location", "X-Cf-Warnings"}
+
+// Normalizer rewrites recorded interactions so that two recordings of the
+// same spec produce the same cassette: GUIDs become sequential placeholders
+// in order of first appearance, timestamps and time-tagged CATS names become
+// constants, tokens are swapped for a replayable token and every endpoint
+// origin becomes StandInOrigin.
+type Normalizer struct {
+	guids map[string]string
+}
+
+func NewNormalizer() *Normalizer {
+	return &Normalizer{guids: map[string]string{}}
+}
+
+func GuidPlaceholder(index int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", index)
+}
+
+func (n *Normalizer) Normalize(interactions []Interaction) []Interaction {
+	origins := originPattern(interactions)
+
+	normalized := make([]Interaction, 0, len(interactions))
+	for _, interaction := range interactions {
+		request := Request{
+			Method: interaction.Request.Method,
+			Path:   n.normalizeText(origins, interaction.Request.Path),
+			Body:   n.normalizeText(origins, interaction.Request.Body),
+		}
+
+		header := map[string][]string{}
+		for _, key := range keptResponseHeaders {
+			values := headerValues(interaction.Response.Header, key)
+			for _, value := range values {
+				header[key] = append(header[key], n.normalizeText(origins, value))
+			}
+		}
+
+		response := Response{
+			StatusCode: interaction.Response.StatusCode,
+			Header:     header,
+			Body:       n.normalizeText(origins, interaction.Response.Body),
+		}
+
+		normalized = append(normalized, Interaction{Request: request, Response: response})
+	}
+
+	return normalized
+}
+
+func (n *Normalizer) normalizeText(origins *regexp.Regexp, text string) string {
+	if origins != nil {
+		text = origins.ReplaceAllString(text, StandInOrigin)
+	}
+
+	text = tokenPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, `"access_token"`) {
+			return fmt.Sprintf(`"access_token":"%s"`, ReplayAccessToken())
+		}
+		return fmt.Sprintf(`"refresh_token":"%s"`, replayRefreshToken)
+	})
+
+	text = guidPattern.ReplaceAllStringFunc(text, func(guid string) string {
+		guid = strings.ToLower(guid)
+		placeholder, ok := n.guids[guid]
+		if !ok {
+			placeholder = GuidPlaceholder(len(n.guids) + 1)
+			n.guids[guid] = placeholder
+		}
+		return placeholder
+	})
+
+	text = timestampPattern.ReplaceAllString(text, normalizedTimestamp)
+	text = timeTagPattern.ReplaceAllString(text, normalizedTimeTag)
+
+	return text
+}
+
+// ReplayAccessToken is the token every replayed UAA response hands out.
+func ReplayAccessToken() string {
+	return gatsHelpers.UnsignedAccessToken(replayUserName, GuidPlaceholder(0), time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// originPattern matches any URL on the system domains of the recorded hosts,
+// so that e.g. the doppler endpoint advertised by /v2/info is rewritten along
+// with the api and uaa endpoints the CLI actually called.
+func originPattern(interactions []Interaction) *regexp.Regexp {
+	domains := map[string]bool{}
+	for _, interaction := range interactions {
+		host := interaction.Request.Host
+		if idx := strings.Index(host, ":"); idx >= 0 {
+			host = host[:idx]
+		}
+		if host == "" {
+			continue
+		}
+
+		domains[regexp.QuoteMeta(host)] = true
+		if idx := strings.Index(host, "."); idx >= 0 && strings.Contains(host[idx+1:], ".") {
+			domains[`[A-Za-z0-9\-\.]*\.`+regexp.QuoteMeta(host[idx+1:])] = true
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil
+	}
+
+	var alternatives []string
+	for domain := range domains {
+		alternatives = append(alternatives, domain)
+	}
+	sort.Strings(alternatives)
+
+	return regexp.MustCompile(`(https?|wss?)://(` + strings.Join(alternatives, "|") + `)(:\d+)?`)
+}
+
+func headerValues(header map[string][]string, key string) []string {
+	for k, values := range header {
+		if strings.EqualFold(k, key) {
+			return values
+		}
+	}
+	return nil
+}
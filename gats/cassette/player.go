@@ -0,0 +1,135 @@
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Player serves the interactions of a cassette from a local HTTP stand-in.
+//
+// Requests are matched on method and path with GUIDs and CATS time tags
+// wildcarded, in recorded order. When a replayed spec sends a GUID or time
+// tag of its own (a fresh random name, say) in place of a recorded
+// placeholder, the placeholder is bound to that value and substituted into
+// every later response, so specs that assert on names they generated still pass.
+type Player struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	cassette Cassette
+	served   []bool
+	bindings map[string]string
+	misses   []string
+}
+
+func NewPlayer() *Player {
+	player := &Player{}
+	player.server = httptest.NewServer(http.HandlerFunc(player.serveHTTP))
+	return player
+}
+
+func (p *Player) URL() string {
+	return p.server.URL
+}
+
+func (p *Player) Close() {
+	p.server.Close()
+}
+
+// Load swaps the cassette being served and forgets all bindings and misses.
+func (p *Player) Load(c Cassette) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.cassette = c
+	p.served = make([]bool, len(c.Interactions))
+	p.bindings = map[string]string{}
+	p.misses = nil
+}
+
+// Misses lists the requests that had no recorded interaction.
+func (p *Player) Misses() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]string{}, p.misses...)
+}
+
+func (p *Player) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := matchKey(r.Method, r.URL.RequestURI())
+
+	index := -1
+	for i, interaction := range p.cassette.Interactions {
+		if matchKey(interaction.Request.Method, interaction.Request.Path) != key {
+			continue
+		}
+
+		index = i
+		if !p.served[i] {
+			break
+		}
+	}
+
+	if index < 0 {
+		miss := fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI())
+		p.misses = append(p.misses, miss)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"code":10000,"description":"gats replay: no recorded interaction for %s","error_code":"CF-NotFound"}`, miss)
+		return
+	}
+
+	interaction := p.cassette.Interactions[index]
+	p.served[index] = true
+
+	p.bind(interaction.Request.Path, r.URL.RequestURI())
+	p.bind(interaction.Request.Body, string(body))
+
+	for key, values := range interaction.Response.Header {
+		for _, value := range values {
+			w.Header().Add(key, p.expand(value))
+		}
+	}
+	w.WriteHeader(interaction.Response.StatusCode)
+	fmt.Fprint(w, p.expand(interaction.Response.Body))
+}
+
+func (p *Player) bind(recorded, actual string) {
+	for _, pattern := range []*regexp.Regexp{guidPattern, timeTagPattern} {
+		placeholders := pattern.FindAllString(recorded, -1)
+		values := pattern.FindAllString(actual, -1)
+
+		for i := 0; i < len(placeholders) && i < len(values); i++ {
+			if placeholders[i] == values[i] {
+				continue
+			}
+			if _, bound := p.bindings[placeholders[i]]; !bound {
+				p.bindings[placeholders[i]] = values[i]
+			}
+		}
+	}
+}
+
+func (p *Player) expand(text string) string {
+	text = strings.Replace(text, StandInOrigin, p.server.URL, -1)
+	for placeholder, value := range p.bindings {
+		text = strings.Replace(text, placeholder, value, -1)
+	}
+	return text
+}
+
+func matchKey(method, path string) string {
+	path = guidPattern.ReplaceAllString(path, "{guid}")
+	path = timeTagPattern.ReplaceAllString(path, "{time}")
+	return method + " " + path
+}
@@ -0,0 +1,58 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Recorder captures a spec's HTTP exchanges by pointing CF_TRACE at a
+// scratch file and converting the dumped requests and responses into a
+// normalized cassette when the spec ends.
+type Recorder struct {
+	directory string
+
+	specText      string
+	traceFile     string
+	originalTrace string
+}
+
+func NewRecorder(directory string) *Recorder {
+	return &Recorder{directory: directory}
+}
+
+func (r *Recorder) Start(specText string) error {
+	traceFile, err := ioutil.TempFile("", "gats-cassette-trace")
+	if err != nil {
+		return err
+	}
+	traceFile.Close()
+
+	r.specText = specText
+	r.traceFile = traceFile.Name()
+	r.originalTrace = os.Getenv("CF_TRACE")
+
+	return os.Setenv("CF_TRACE", r.traceFile)
+}
+
+// Stop restores CF_TRACE and writes the cassette for the spec passed to Start.
+func (r *Recorder) Stop() (Cassette, error) {
+	defer os.Remove(r.traceFile)
+
+	err := os.Setenv("CF_TRACE", r.originalTrace)
+	if err != nil {
+		return Cassette{}, err
+	}
+
+	trace, err := ioutil.ReadFile(r.traceFile)
+	if err != nil {
+		return Cassette{}, err
+	}
+
+	c := Cassette{
+		Name:         r.specText,
+		Interactions: NewNormalizer().Normalize(ParseTrace(string(trace))),
+	}
+
+	return c, c.Save(filepath.Join(r.directory, FileName(r.specText)))
+}
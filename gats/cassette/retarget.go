@@ -0,0 +1,85 @@
+package cassette
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// endpointConfigKeys are the fields of the CLI's config.json that hold URLs.
+var endpointConfigKeys = []string{
+	"Target",
+	"AuthorizationEndpoint",
+	"UaaEndpoint",
+	"LoggregatorEndPoint",
+	"DopplerEndPoint",
+	"RoutingAPIEndpoint",
+}
+
+// RetargetCfHome points every endpoint persisted in $cfHome/.cf/config.json at url.
+// A CF_HOME without a config is left alone.
+func RetargetCfHome(cfHome, url string) error {
+	path := filepath.Join(cfHome, ".cf", "config.json")
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	config := map[string]interface{}{}
+	err = json.Unmarshal(contents, &config)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range endpointConfigKeys {
+		if value, ok := config[key].(string); ok && value != "" {
+			config[key] = url
+		}
+	}
+
+	contents, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, contents, 0600)
+}
+
+// RetargetCommand rewrites the API endpoint given to `cf api` and `cf login -a`
+// so that a CF_HOME set up during replay targets the stand-in at url.
+func RetargetCommand(cmd *exec.Cmd, url string) *exec.Cmd {
+	if len(cmd.Args) < 2 {
+		return cmd
+	}
+
+	name := filepath.Base(cmd.Args[0])
+	if name != "cf" && name != "cf.exe" {
+		return cmd
+	}
+
+	args := cmd.Args
+	switch args[1] {
+	case "api":
+		for i := 2; i < len(args); i++ {
+			if !strings.HasPrefix(args[i], "-") {
+				args[i] = url
+				break
+			}
+		}
+	case "login", "l":
+		for i := 2; i+1 < len(args); i++ {
+			if args[i] == "-a" {
+				args[i+1] = url
+			}
+		}
+	}
+
+	return cmd
+}
@@ -0,0 +1,127 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http/httputil"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ansiEscapes  = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	traceMarkers = regexp.MustCompile(`(?m)^(REQUEST|RESPONSE): \[[^\]]*\]\r?\n`)
+)
+
+// ParseTrace reads the output of the CLI's request dumper (CF_TRACE) and
+// pairs every dumped request with the response that follows it.
+func ParseTrace(trace string) []Interaction {
+	trace = ansiEscapes.ReplaceAllString(trace, "")
+
+	markers := traceMarkers.FindAllStringSubmatchIndex(trace, -1)
+
+	var interactions []Interaction
+	var pending *Request
+	for i, marker := range markers {
+		end := len(trace)
+		if i+1 < len(markers) {
+			end = markers[i+1][0]
+		}
+
+		dump := strings.TrimSuffix(strings.TrimSuffix(trace[marker[1]:end], "\n"), "\n")
+
+		switch trace[marker[2]:marker[3]] {
+		case "REQUEST":
+			request, ok := parseRequestDump(dump)
+			if ok {
+				pending = &request
+			} else {
+				pending = nil
+			}
+		case "RESPONSE":
+			response, ok := parseResponseDump(dump)
+			if ok && pending != nil {
+				interactions = append(interactions, Interaction{Request: *pending, Response: response})
+			}
+			pending = nil
+		}
+	}
+
+	return interactions
+}
+
+func parseRequestDump(dump string) (Request, bool) {
+	startLine, header, body := splitDump(dump)
+
+	fields := strings.Fields(startLine)
+	if len(fields) < 2 {
+		return Request{}, false
+	}
+
+	return Request{
+		Method: fields[0],
+		Host:   firstHeader(header, "Host"),
+		Path:   fields[1],
+		Body:   body,
+	}, true
+}
+
+func parseResponseDump(dump string) (Response, bool) {
+	startLine, header, body := splitDump(dump)
+
+	fields := strings.Fields(startLine)
+	if len(fields) < 2 {
+		return Response{}, false
+	}
+
+	statusCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Response{}, false
+	}
+
+	if strings.Contains(strings.ToLower(firstHeader(header, "Transfer-Encoding")), "chunked") {
+		decoded, err := ioutil.ReadAll(httputil.NewChunkedReader(strings.NewReader(body)))
+		if err == nil {
+			body = string(decoded)
+		}
+		delete(header, "Transfer-Encoding")
+	}
+
+	return Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       body,
+	}, true
+}
+
+func splitDump(dump string) (string, map[string][]string, string) {
+	dump = strings.Replace(dump, "\r\n", "\n", -1)
+
+	head, body := dump, ""
+	if idx := strings.Index(dump, "\n\n"); idx >= 0 {
+		head, body = dump[:idx], dump[idx+2:]
+	}
+
+	lines := strings.Split(head, "\n")
+	header := map[string][]string{}
+	for _, line := range lines[1:] {
+		idx := strings.Index(line, ":")
+		if idx < 0 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:idx])
+		header[key] = append(header[key], strings.TrimSpace(line[idx+1:]))
+	}
+
+	return lines[0], header, body
+}
+
+func firstHeader(header map[string][]string, key string) string {
+	for k, values := range header {
+		if strings.EqualFold(k, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
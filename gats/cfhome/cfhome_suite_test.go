@@ -0,0 +1,16 @@
+package cfhome_test
+
+import (
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/ab"
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+
+	"testing"
+)
+
+var _ = ab.RegisterHooks("cfhome")
+var _ = strict.RegisterHooks()
+
+func TestCfHome(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "CF_HOME Suite", "cfhome")
+}
@@ -0,0 +1,299 @@
+package cfhome_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cli/cf/configuration/pluginconfig"
+	"github.com/cloudfoundry/cli/plugin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// These specs never reach a foundation: every command used here only reads
+// and writes CF_HOME, so the seeded target is never dialled.
+var _ = Describe("CF_HOME config files", func() {
+	const (
+		target  = "https://api.gats-cfhome.example.com"
+		org     = "gats-cfhome-org"
+		space   = "gats-cfhome-space"
+		timeout = 10 * time.Second
+	)
+
+	var (
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	seededConfig := func() gatsHelpers.CfConfigData {
+		return gatsHelpers.CfConfigData{
+			Target:             target,
+			APIVersion:         "2.54.0",
+			AccessToken:        "bearer " + gatsHelpers.UnsignedAccessToken("gats-cfhome-user", "00000000-0000-0000-0000-000000000001", time.Now().Add(time.Hour)),
+			OrganizationFields: gatsHelpers.CfOrganizationFields{GUID: "00000000-0000-0000-0000-000000000002", Name: org},
+			SpaceFields:        gatsHelpers.CfSpaceFields{GUID: "00000000-0000-0000-0000-000000000003", Name: space},
+			AsyncTimeout:       5,
+			PluginRepos:        []gatsHelpers.CfPluginRepo{{Name: "gats-repo", URL: "https://plugins.gats-cfhome.example.com"}},
+		}
+	}
+
+	seededPlugins := func() pluginconfig.PluginData {
+		return pluginconfig.PluginData{Plugins: map[string]pluginconfig.PluginMetadata{
+			"GatsCfHomePlugin": {
+				Location: filepath.Join(cfHome, ".cf", "plugins", "gats-cfhome-plugin"),
+				Version:  plugin.VersionType{Major: 1, Minor: 2, Build: 3},
+				Commands: []plugin.Command{{Name: "gats-cfhome-command", HelpText: "does nothing"}},
+			},
+		}}
+	}
+
+	// expectCleanExit asserts the CLI handled the state it found: it either
+	// succeeded or failed with a message, and never crashed.
+	expectCleanExit := func(session *Session) {
+		Expect(session.ExitCode()).To(BeNumerically(">=", 0))
+		Expect(session.ExitCode()).To(BeNumerically("<=", 1))
+
+		output := string(session.Out.Contents()) + string(session.Err.Contents())
+		Expect(output).NotTo(ContainSubstring("Something unexpected happened"))
+		Expect(output).NotTo(ContainSubstring("goroutine "))
+	}
+
+	expectTargetKept := func() {
+		session := Cf("target").Wait(timeout)
+		Expect(session).To(Exit(0))
+		Expect(session.Out).To(Say("gats-cfhome.example.com"))
+		Expect(session.Out).To(Say(org))
+	}
+
+	expectPluginsKept := func() {
+		session := Cf("plugins").Wait(timeout)
+		Expect(session).To(Exit(0))
+		Expect(session.Out).To(Say("GatsCfHomePlugin"))
+	}
+
+	skipUnlessPermissionsApply := func() {
+		if runtime.GOOS == "windows" {
+			Skip("requires POSIX file permissions")
+		}
+		if os.Geteuid() == 0 {
+			Skip("requires a non-root user; root ignores file permissions")
+		}
+	}
+
+	BeforeEach(func() {
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-cfhome")
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+	})
+
+	Context("with a config written by a newer CLI", func() {
+		BeforeEach(func() {
+			Expect(gatsHelpers.WriteCfConfig(cfHome, seededConfig())).To(Succeed())
+			contents, err := ioutil.ReadFile(gatsHelpers.CfConfigPath(cfHome))
+			Expect(err).NotTo(HaveOccurred())
+
+			withUnknownFields := append([]byte(`{"GatsFutureSetting": {"enabled": true}, "GatsFutureList": [1, 2],`), contents[1:]...)
+			Expect(ioutil.WriteFile(gatsHelpers.CfConfigPath(cfHome), withUnknownFields, 0600)).To(Succeed())
+		})
+
+		It("ignores the unknown fields and keeps the target through a write", func() {
+			expectTargetKept()
+
+			Eventually(Cf("config", "--async-timeout", "7"), timeout).Should(Exit(0))
+
+			data, err := gatsHelpers.ReadCfConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Target).To(Equal(target))
+			Expect(data.OrganizationFields.Name).To(Equal(org))
+			Expect(data.SpaceFields.Name).To(Equal(space))
+			Expect(data.AsyncTimeout).To(Equal(uint(7)))
+			Expect(data.PluginRepos).To(Equal(seededConfig().PluginRepos))
+		})
+	})
+
+	Context("with a config from an older CLI version", func() {
+		BeforeEach(func() {
+			Expect(gatsHelpers.WritePluginConfig(cfHome, seededPlugins())).To(Succeed())
+			Expect(ioutil.WriteFile(gatsHelpers.CfConfigPath(cfHome), []byte(`{
+  "ConfigVersion": 2,
+  "Target": "`+target+`",
+  "ApiVersion": "2.0.0",
+  "AccessToken": "bearer old-token",
+  "OrganizationFields": {"Name": "`+org+`"}
+}`), 0600)).To(Succeed())
+		})
+
+		It("starts from an empty version 3 config and reports that no API is set", func() {
+			session := Cf("target").Wait(timeout)
+			expectCleanExit(session)
+			Expect(session).To(Exit(1))
+			Expect(session.Out).To(Say("No API endpoint set"))
+
+			Eventually(Cf("config", "--async-timeout", "3"), timeout).Should(Exit(0))
+
+			data, err := gatsHelpers.ReadCfConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.ConfigVersion).To(Equal(3))
+			Expect(data.AsyncTimeout).To(Equal(uint(3)))
+		})
+
+		It("keeps the plugins", func() {
+			expectPluginsKept()
+		})
+	})
+
+	// A slice rather than a map: every parallel node must build the same tree.
+	corruptions := []struct {
+		description string
+		corrupt     func(contents []byte) []byte
+	}{
+		{"truncated", func(contents []byte) []byte { return contents[:len(contents)/2] }},
+		{"zero-filled after a disk-full event", func(contents []byte) []byte { return make([]byte, len(contents)) }},
+		{"empty", func(contents []byte) []byte { return nil }},
+	}
+
+	for _, corruption := range corruptions {
+		description, corrupt := corruption.description, corruption.corrupt
+
+		Context("with a "+description+" config", func() {
+			BeforeEach(func() {
+				Expect(gatsHelpers.WriteCfConfig(cfHome, seededConfig())).To(Succeed())
+				Expect(gatsHelpers.WritePluginConfig(cfHome, seededPlugins())).To(Succeed())
+
+				contents, err := ioutil.ReadFile(gatsHelpers.CfConfigPath(cfHome))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ioutil.WriteFile(gatsHelpers.CfConfigPath(cfHome), corrupt(contents), 0600)).To(Succeed())
+			})
+
+			It("repairs it into a valid config without crashing", func() {
+				expectCleanExit(Cf("target").Wait(timeout))
+
+				data, err := gatsHelpers.ReadCfConfig(cfHome)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data.ConfigVersion).To(Equal(3))
+			})
+
+			It("keeps the plugins", func() {
+				expectCleanExit(Cf("target").Wait(timeout))
+				expectPluginsKept()
+			})
+		})
+
+		Context("with a "+description+" plugin config", func() {
+			BeforeEach(func() {
+				Expect(gatsHelpers.WriteCfConfig(cfHome, seededConfig())).To(Succeed())
+				Expect(gatsHelpers.WritePluginConfig(cfHome, seededPlugins())).To(Succeed())
+
+				contents, err := ioutil.ReadFile(gatsHelpers.PluginConfigPath(cfHome))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ioutil.WriteFile(gatsHelpers.PluginConfigPath(cfHome), corrupt(contents), 0600)).To(Succeed())
+			})
+
+			It("lists plugins without crashing and keeps the target", func() {
+				expectCleanExit(Cf("plugins").Wait(timeout))
+				expectTargetKept()
+
+				_, err := gatsHelpers.ReadPluginConfig(cfHome)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	}
+
+	Context("with a read-only config", func() {
+		BeforeEach(func() {
+			skipUnlessPermissionsApply()
+
+			Expect(gatsHelpers.WriteCfConfig(cfHome, seededConfig())).To(Succeed())
+			Expect(os.Chmod(gatsHelpers.CfConfigPath(cfHome), 0400)).To(Succeed())
+		})
+
+		It("still reads the target", func() {
+			expectTargetKept()
+		})
+
+		It("reports a failed write and leaves the file as it was", func() {
+			before, err := ioutil.ReadFile(gatsHelpers.CfConfigPath(cfHome))
+			Expect(err).NotTo(HaveOccurred())
+
+			session := Cf("config", "--async-timeout", "7").Wait(timeout)
+			expectCleanExit(session)
+			Expect(session).To(Exit(1))
+			Expect(session.Out).To(Say("permission denied"))
+
+			after, err := ioutil.ReadFile(gatsHelpers.CfConfigPath(cfHome))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(after).To(Equal(before))
+		})
+	})
+
+	Context("with an unreadable config", func() {
+		BeforeEach(func() {
+			skipUnlessPermissionsApply()
+
+			Expect(gatsHelpers.WriteCfConfig(cfHome, seededConfig())).To(Succeed())
+			Expect(os.Chmod(gatsHelpers.CfConfigPath(cfHome), 0000)).To(Succeed())
+		})
+
+		It("reports the error instead of overwriting the config", func() {
+			session := Cf("target").Wait(timeout)
+			expectCleanExit(session)
+			Expect(session).To(Exit(1))
+			Expect(session.Out).To(Say("permission denied"))
+
+			Expect(os.Chmod(gatsHelpers.CfConfigPath(cfHome), 0600)).To(Succeed())
+			data, err := gatsHelpers.ReadCfConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.Target).To(Equal(target))
+		})
+	})
+
+	Context("without a .cf directory", func() {
+		It("creates the directory and config privately", func() {
+			Eventually(Cf("config", "--async-timeout", "3"), timeout).Should(Exit(0))
+
+			data, err := gatsHelpers.ReadCfConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data.AsyncTimeout).To(Equal(uint(3)))
+
+			if runtime.GOOS != "windows" {
+				info, err := os.Stat(filepath.Join(cfHome, ".cf"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+
+				info, err = os.Stat(gatsHelpers.CfConfigPath(cfHome))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+			}
+		})
+	})
+
+	Context("when CF_HOME does not exist", func() {
+		var missing string
+
+		BeforeEach(func() {
+			missing = filepath.Join(cfHome, "missing")
+			os.Setenv("CF_HOME", missing)
+		})
+
+		It("reports the missing directory instead of creating it", func() {
+			session := Cf("target").Wait(timeout)
+			expectCleanExit(session)
+			Expect(session).To(Exit(1))
+			Expect(session.Out).To(Say("Error locating CF_HOME folder"))
+
+			_, err := os.Stat(missing)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package cfhome_test
+
+import (
+	"net/http"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// These specs check what each command writes to config.json, against a
+// stand-in instead of a foundation.
+var _ = Describe("the config commands persist", func() {
+	const timeout = 10 * time.Second
+
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	readConfig := func() gatsHelpers.CfConfigData {
+		data, err := gatsHelpers.ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	login := func() {
+		Eventually(Cf("login", "-a", server.URL(), "-u", standin.Username, "-p", standin.Password, "-o", standin.OrgName, "-s", standin.SpaceName), timeout).Should(Exit(0))
+	}
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-cfhome")
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	It("the target and API version after api", func() {
+		Eventually(Cf("api", server.URL()), timeout).Should(Exit(0))
+
+		data := readConfig()
+		Expect(data.ConfigVersion).To(Equal(3))
+		Expect(data.Target).To(Equal(server.URL()))
+		Expect(data.APIVersion).To(Equal(standin.APIVersion))
+		Expect(data.AuthorizationEndpoint).To(Equal(server.URL()))
+		Expect(data.SSLDisabled).To(BeFalse())
+		Expect(data.AccessToken).To(BeEmpty())
+	})
+
+	It("that SSL validation is skipped after api --skip-ssl-validation", func() {
+		Eventually(Cf("api", server.URL(), "--skip-ssl-validation"), timeout).Should(Exit(0))
+
+		Expect(readConfig().SSLDisabled).To(BeTrue())
+	})
+
+	It("the tokens, org and space after login", func() {
+		login()
+
+		data := readConfig()
+		Expect(data.Target).To(Equal(server.URL()))
+		Expect(data.AccessToken).To(HavePrefix("bearer "))
+		Expect(data.RefreshToken).NotTo(BeEmpty())
+		Expect(data.OrganizationFields.GUID).To(Equal(standin.OrgGuid))
+		Expect(data.OrganizationFields.Name).To(Equal(standin.OrgName))
+		Expect(data.SpaceFields.GUID).To(Equal(standin.SpaceGuid))
+		Expect(data.SpaceFields.Name).To(Equal(standin.SpaceName))
+	})
+
+	It("only the target after logout", func() {
+		login()
+		Eventually(Cf("logout"), timeout).Should(Exit(0))
+
+		data := readConfig()
+		Expect(data.Target).To(Equal(server.URL()))
+		Expect(data.APIVersion).To(Equal(standin.APIVersion))
+		Expect(data.AccessToken).To(BeEmpty())
+		Expect(data.RefreshToken).To(BeEmpty())
+		Expect(data.OrganizationFields).To(Equal(gatsHelpers.CfOrganizationFields{}))
+		Expect(data.SpaceFields).To(Equal(gatsHelpers.CfSpaceFields{}))
+	})
+
+	It("the async timeout, color and locale after config", func() {
+		Eventually(Cf("config", "--async-timeout", "9", "--color", "false", "--locale", "fr-FR"), timeout).Should(Exit(0))
+
+		data := readConfig()
+		Expect(data.AsyncTimeout).To(Equal(uint(9)))
+		Expect(data.ColorEnabled).To(Equal("false"))
+		Expect(data.Locale).To(Equal("fr-FR"))
+
+		Eventually(Cf("config", "--locale", "CLEAR"), timeout).Should(Exit(0))
+		Expect(readConfig().Locale).To(BeEmpty())
+	})
+
+	It("the repo after add-plugin-repo", func() {
+		server.Handle("GET", "/list", func(w http.ResponseWriter, r *http.Request) {
+			standin.WriteJSON(w, http.StatusOK, map[string]interface{}{"plugins": []interface{}{}})
+		})
+
+		Eventually(Cf("add-plugin-repo", "gats-repo", server.URL()), timeout).Should(Exit(0))
+
+		Expect(readConfig().PluginRepos).To(Equal([]gatsHelpers.CfPluginRepo{{Name: "gats-repo", URL: server.URL()}}))
+	})
+})
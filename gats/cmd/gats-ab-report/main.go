@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/ab"
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+func main() {
+	gatsDirectory := flag.String("gats-dir", "gats", "path to the gats directory")
+	suite := flag.String("suite", "plugin", "suite whose A/B results to compare")
+	flag.Parse()
+
+	config, _, err := gatsHelpers.Load(os.Getenv("CONFIG"))
+	if err != nil {
+		fail(err)
+	}
+
+	// Suites run from their own directory, so a relative artifacts
+	// directory is relative to it.
+	artifactsDirectory := config.ArtifactsDirectory
+	if !filepath.IsAbs(artifactsDirectory) {
+		artifactsDirectory = filepath.Join(*gatsDirectory, *suite, artifactsDirectory)
+	}
+	resultsDirectory := ab.ResultsDirectory(artifactsDirectory, *suite)
+
+	runsA, err := ab.LoadRuns(resultsDirectory, ab.SideA)
+	if err != nil {
+		fail(err)
+	}
+
+	runsB, err := ab.LoadRuns(resultsDirectory, ab.SideB)
+	if err != nil {
+		fail(err)
+	}
+
+	report := ab.Compare(runsA, runsB)
+	fmt.Print(report)
+
+	if report.Differs() {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+func main() {
+	gatsDirectory := flag.String("gats-dir", "gats", "path to the gats directory")
+	flag.Parse()
+
+	report := gatsHelpers.Preflight(gatsHelpers.PreflightOptions{GatsDirectory: *gatsDirectory})
+	fmt.Print(report)
+
+	if report.Failed() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,288 @@
+package concurrency_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	workersEnvVar = "GATS_STRESS_WORKERS"
+	roundsEnvVar  = "GATS_STRESS_ROUNDS"
+
+	commandTimeout = 30 * time.Second
+)
+
+// invocation is one cf command run by one worker.
+type invocation struct {
+	worker   int
+	round    int
+	args     []string
+	started  time.Time
+	finished time.Time
+	exitCode int
+	output   string
+}
+
+func (i invocation) String() string {
+	return fmt.Sprintf("worker %d round %d: cf %s", i.worker, i.round, strings.Join(i.args, " "))
+}
+
+func (i invocation) overlaps(from, to time.Time) bool {
+	return i.started.Before(to) && i.finished.After(from)
+}
+
+// problem is something that went wrong during a stress run, with the window
+// in which it happened.
+type problem struct {
+	kind   string
+	detail string
+	from   time.Time
+	to     time.Time
+}
+
+var (
+	configCorruptionSignatures = []string{"Error read/writing config", "Config error", "invalid character", "unexpected end of JSON input"}
+	lostRefreshSignatures      = []string{"Authentication has expired", "Please log back in"}
+	rpcClashSignatures         = []string{"address already in use", "connection refused", "connection reset"}
+)
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// stress runs workers goroutines that each run rounds commands from
+// commands, cycling through them from a different offset per worker.
+func stress(workers, rounds int, commands [][]string) []invocation {
+	var (
+		mutex       sync.Mutex
+		invocations []invocation
+		wg          sync.WaitGroup
+	)
+
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer GinkgoRecover()
+			defer wg.Done()
+
+			for round := 0; round < rounds; round++ {
+				args := commands[(worker+round)%len(commands)]
+
+				started := time.Now()
+				session := Cf(args...).Wait(commandTimeout)
+				record := invocation{
+					worker:   worker,
+					round:    round,
+					args:     args,
+					started:  started,
+					finished: time.Now(),
+					exitCode: session.ExitCode(),
+					output:   string(session.Out.Contents()) + string(session.Err.Contents()),
+				}
+
+				mutex.Lock()
+				invocations = append(invocations, record)
+				mutex.Unlock()
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+	return invocations
+}
+
+// watchConfig reads config.json until stop is closed and reports every read
+// that doesn't parse: a torn write another process could have read too.
+func watchConfig(cfHome string, stop chan struct{}) chan []problem {
+	result := make(chan []problem, 1)
+
+	go func() {
+		var problems []problem
+		for {
+			select {
+			case <-stop:
+				result <- problems
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+
+			readAt := time.Now()
+			_, err := gatsHelpers.ReadCfConfig(cfHome)
+			if err != nil && !os.IsNotExist(err) {
+				problems = append(problems, problem{kind: "config.json corruption", detail: "torn read: " + err.Error(), from: readAt, to: time.Now()})
+			}
+		}
+	}()
+
+	return result
+}
+
+func classify(invocations []invocation, pluginCommand string) []problem {
+	var problems []problem
+
+	for _, i := range invocations {
+		kind := ""
+		switch {
+		case containsAny(i.output, configCorruptionSignatures):
+			kind = "config.json corruption"
+		case containsAny(i.output, lostRefreshSignatures):
+			kind = "lost token refresh"
+		case i.args[0] == pluginCommand && (containsAny(i.output, rpcClashSignatures) || !strings.Contains(i.output, "Done "+pluginCommand)):
+			kind = "plugin RPC port clash"
+		case i.exitCode != 0:
+			kind = "failed command"
+		default:
+			continue
+		}
+
+		problems = append(problems, problem{kind: kind, detail: fmt.Sprintf("%s exited %d:\n%s", i, i.exitCode, indent(i.output)), from: i.started, to: i.finished})
+	}
+
+	return problems
+}
+
+// interleavings renders every problem with the invocations that were running
+// while it happened, relative to the start of the run.
+func interleavings(start time.Time, problems []problem, invocations []invocation) string {
+	if len(problems) == 0 {
+		return ""
+	}
+
+	buffer := &bytes.Buffer{}
+	for _, p := range problems {
+		fmt.Fprintf(buffer, "%s at +%s..+%s\n%s\n  running at the time:\n", p.kind, p.from.Sub(start), p.to.Sub(start), indent(p.detail))
+		for _, i := range invocations {
+			if i.overlaps(p.from, p.to) {
+				fmt.Fprintf(buffer, "    +%-12s +%-12s %s (exit %d)\n", i.started.Sub(start), i.finished.Sub(start), i, i.exitCode)
+			}
+		}
+	}
+
+	return buffer.String()
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func indent(s string) string {
+	return "    " + strings.Replace(strings.TrimRight(s, "\n"), "\n", "\n    ", -1)
+}
+
+var _ = Describe("cf invocations sharing one CF_HOME", func() {
+	const pluginCommand = "GetCurrentOrg"
+
+	var (
+		server *standin.Server
+		cfHome string
+
+		workers int
+		rounds  int
+
+		restoreCfHome func()
+	)
+
+	commands := [][]string{
+		{"target"},
+		{"apps"},
+		{"curl", "/v2/info"},
+		{pluginCommand},
+	}
+
+	run := func(commands [][]string) {
+		stop := make(chan struct{})
+		watched := watchConfig(cfHome, stop)
+
+		start := time.Now()
+		invocations := stress(workers, rounds, commands)
+		close(stop)
+
+		problems := append(<-watched, classify(invocations, pluginCommand)...)
+
+		data, err := gatsHelpers.ReadCfConfig(cfHome)
+		if err != nil {
+			problems = append(problems, problem{kind: "config.json corruption", detail: "after the run: " + err.Error(), from: start, to: time.Now()})
+		} else if data.Target != server.URL() || data.OrganizationFields.Name != standin.OrgName || data.SpaceFields.Name != standin.SpaceName {
+			problems = append(problems, problem{kind: "config.json corruption", detail: fmt.Sprintf("after the run the target is %q, org %q, space %q", data.Target, data.OrganizationFields.Name, data.SpaceFields.Name), from: start, to: time.Now()})
+		}
+
+		report := interleavings(start, problems, invocations)
+		fmt.Fprint(GinkgoWriter, report)
+		Expect(problems).To(BeEmpty(), report)
+	}
+
+	BeforeEach(func() {
+		workers = envInt(workersEnvVar, 8)
+		rounds = envInt(roundsEnvVar, 5)
+
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-concurrency")
+
+		Eventually(Cf("api", server.URL()), commandTimeout).Should(Exit(0))
+		Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+		Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	It("keeps config.json intact", func() {
+		run(commands)
+	})
+
+	It("starts a plugin RPC server per invocation without port clashes", func() {
+		run([][]string{{pluginCommand}})
+	})
+
+	Context("when every invocation starts with an expired token", func() {
+		BeforeEach(func() {
+			server.SetTokenLifetime(time.Second)
+			Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+			Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+			time.Sleep(server.TokenLifetime() + 100*time.Millisecond)
+		})
+
+		It("refreshes without losing the session", func() {
+			run(commands)
+
+			granted, _ := server.Refreshes()
+			Expect(granted).To(BeNumerically(">", 0))
+		})
+
+		Context("and the UAA rotates refresh tokens", func() {
+			BeforeEach(func() {
+				server.SetRotateRefreshTokens(true)
+			})
+
+			It("refreshes without losing the session", func() {
+				run(commands)
+			})
+		})
+	})
+})
@@ -0,0 +1,224 @@
+package crash_test
+
+import (
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 30 * time.Second
+
+	bannerTitle = "Something unexpected happened. This is a bug in cf."
+
+	// privateDataPlaceholder is what cf/trace puts in place of secrets.
+	privateDataPlaceholder = "[PRIVATE DATA HIDDEN]"
+)
+
+var (
+	orgsPath    = "/v2/organizations"
+	spacesPath  = "/v2/organizations/" + standin.OrgGuid + "/spaces"
+	summaryPath = "/v2/spaces/" + standin.SpaceGuid + "/summary"
+)
+
+// expectBanner checks that the CLI recovered from the panic and printed
+// panicprinter.CrashDialog once, for command, with errorLines in its Error
+// section and a stack trace through frame.
+func expectBanner(session *Session, command string, errorLines []string, frame string) {
+	output := string(session.Out.Contents())
+	Expect(strings.Count(output, bannerTitle)).To(Equal(1), "expected one crash banner in:\n%s", output)
+
+	lines := []string{bannerTitle, "Command", command, "CLI Version", "Error"}
+	lines = append(lines, errorLines...)
+	lines = append(lines, "Stack Trace", "goroutine ", frame, "Your Platform Details")
+	gatsHelpers.ExpectLinesInOrder(output, lines)
+
+	Expect(string(session.Err.Contents())).NotTo(ContainSubstring("panic:"), "the panic escaped the CLI's recovery")
+}
+
+func clearTarget(cfHome string) {
+	config, err := gatsHelpers.ReadCfConfig(cfHome)
+	Expect(err).NotTo(HaveOccurred())
+
+	config.OrganizationFields = gatsHelpers.CfOrganizationFields{}
+	config.SpaceFields = gatsHelpers.CfSpaceFields{}
+	Expect(gatsHelpers.WriteCfConfig(cfHome, config)).To(Succeed())
+}
+
+// expectTargeted checks that config.json still parses and targets the
+// stand-in's org and space with the tokens it had before.
+func expectTargeted(cfHome string, before gatsHelpers.CfConfigData) {
+	after, err := gatsHelpers.ReadCfConfig(cfHome)
+	Expect(err).NotTo(HaveOccurred(), "config.json was left unreadable")
+
+	Expect(after.Target).To(Equal(before.Target))
+	Expect(after.AccessToken).To(Equal(before.AccessToken))
+	Expect(after.RefreshToken).To(Equal(before.RefreshToken))
+	Expect(after.OrganizationFields.GUID).To(Equal(standin.OrgGuid))
+	Expect(after.SpaceFields.GUID).To(Equal(standin.SpaceGuid))
+}
+
+var _ = Describe("crash reporting", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		seeded gatsHelpers.CfConfigData
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-crash")
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+
+		var err error
+		seeded, err = gatsHelpers.ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	Context("when a Cloud Controller response makes the CLI panic", func() {
+		// The CLI works out the base domain of every redirect target by
+		// indexing the host's last two labels, which panics for a
+		// single-label host.
+		var singleLabelHost string
+
+		BeforeEach(func() {
+			_, port, err := net.SplitHostPort(server.Addr())
+			Expect(err).NotTo(HaveOccurred())
+			singleLabelHost = "http://localhost:" + port
+		})
+
+		It("prints the crash banner and exits 1", func() {
+			server.Inject("GET", summaryPath, standin.RedirectFault(singleLabelHost))
+
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			expectBanner(session, "cf apps", []string{"index out of range"}, "getBaseDomain")
+		})
+
+		It("leaves the config usable", func() {
+			server.Inject("GET", summaryPath, standin.RedirectFault(singleLabelHost))
+
+			Eventually(Cf("apps"), commandTimeout).Should(Exit(1))
+			expectTargeted(cfHome, seeded)
+
+			server.ClearFaults()
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(0))
+			Expect(session).To(Say("No apps found"))
+		})
+
+		It("keeps tokens out of the banner and the trace", func() {
+			server.Inject("GET", summaryPath, standin.RedirectFault(singleLabelHost))
+
+			originalTrace := os.Getenv("CF_TRACE")
+			os.Setenv("CF_TRACE", "true")
+			defer os.Setenv("CF_TRACE", originalTrace)
+
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(1))
+			expectBanner(session, "cf apps", []string{"index out of range"}, "getBaseDomain")
+
+			output := string(session.Out.Contents()) + string(session.Err.Contents())
+			Expect(output).To(ContainSubstring(privateDataPlaceholder))
+			Expect(output).NotTo(ContainSubstring(strings.TrimPrefix(seeded.AccessToken, "bearer ")))
+			Expect(output).NotTo(ContainSubstring(seeded.RefreshToken))
+		})
+
+		It("keeps the password out of the banner when logging in", func() {
+			server.Inject("GET", orgsPath, standin.RedirectFault(singleLabelHost))
+
+			session := Cf("login", "-a", server.URL(), "-u", standin.Username, "-p", standin.Password, "-o", standin.OrgName, "-s", standin.SpaceName)
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			output := string(session.Out.Contents()) + string(session.Err.Contents())
+			Expect(output).To(ContainSubstring(bannerTitle))
+			Expect(output).NotTo(ContainSubstring(standin.Password))
+
+			config, err := gatsHelpers.ReadCfConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred(), "config.json was left unreadable")
+			Expect(config.Target).To(Equal(server.URL()))
+			Expect(server.IsValidAccessToken(config.AccessToken)).To(BeTrue(), "the tokens from the login before the panic weren't kept")
+		})
+	})
+
+	Context("when CF_RAISE_ERROR_ON_WARNINGS turns warnings into a panic", func() {
+		const warning = "Space gats-space has almost used up its memory quota"
+
+		var originalRaiseError string
+
+		BeforeEach(func() {
+			originalRaiseError = os.Getenv(strict.RaiseErrorEnvVar)
+			os.Setenv(strict.RaiseErrorEnvVar, "true")
+
+			server.Warn("GET", spacesPath, warning)
+			clearTarget(cfHome)
+		})
+
+		AfterEach(func() {
+			os.Setenv(strict.RaiseErrorEnvVar, originalRaiseError)
+		})
+
+		It("prints the warnings in the crash banner and exits 1", func() {
+			session := Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName)
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			expectBanner(session, "cf target -o "+standin.OrgName+" -s "+standin.SpaceName, []string{warning}, "PrintWarnings")
+
+			warnings, raised := strict.ParseRaisedWarnings(string(session.Out.Contents()))
+			Expect(raised).To(BeTrue())
+			Expect(warnings).To(Equal([]string{warning}))
+		})
+
+		It("keeps the config the command wrote before panicking", func() {
+			Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(1))
+
+			expectTargeted(cfHome, seeded)
+		})
+	})
+
+	Context("when a plugin panics", func() {
+		It("exits 1 with the plugin's panic rather than the CLI's banner", func() {
+			session := Cf("Panic")
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			Expect(session.Err).To(Say("panic: gats plugin panic"))
+			Expect(session.Err).To(Say("goroutine "))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring(bannerTitle))
+
+			expectTargeted(cfHome, seeded)
+		})
+
+		It("keeps the config written by the cf command the plugin ran", func() {
+			clearTarget(cfHome)
+
+			session := Cf("PanicAfterCliCommand", "target", "-o", standin.OrgName, "-s", standin.SpaceName)
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			Expect(session.Err).To(Say("panic: gats plugin panic after cf target -o " + standin.OrgName + " -s " + standin.SpaceName))
+			expectTargeted(cfHome, seeded)
+		})
+	})
+})
@@ -0,0 +1,11 @@
+package exitcodes_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	"testing"
+)
+
+func TestExitCodes(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Exit Codes Suite", "exitcodes")
+}
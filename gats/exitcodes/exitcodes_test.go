@@ -0,0 +1,161 @@
+package exitcodes_test
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 1 * time.Minute
+
+	appName = "gats-app"
+	appGuid = "00000000-0000-0000-0000-00000000a005"
+)
+
+// state puts the stand-in and CF_HOME into the situation a row runs in.
+type state func(server *standin.Server, cfHome string)
+
+// scenario is one row of the matrix: the situation, the cf invocation, its
+// exit code, and the lines it prints, in order, to stdout. The cf 6 UI
+// prints everything, failures included, to stdout and nothing to stderr.
+type scenario struct {
+	description string
+	state       state
+	args        string
+	exitCode    int
+	lines       []string
+}
+
+var scenarios = []scenario{
+	{"success", targeted, "apps", 0, []string{"Getting apps in org gats-org / space gats-space as gats-user...", "OK"}},
+	{"unknown command", targeted, "gats-no-such-command", 1, []string{"FAILED", "'gats-no-such-command' is not a registered command. See 'cf help'"}},
+	{"missing argument", targeted, "app", 1, []string{"FAILED", "Incorrect Usage. Requires an argument", "USAGE:"}},
+	{"unknown flag", targeted, "apps --gats-no-such-flag", 1, []string{"FAILED", "Incorrect Usage", "gats-no-such-flag"}},
+	{"invalid flag value", targeted, "scale gats-app -m lots -f", 1, []string{"FAILED", "Invalid memory limit: lots"}},
+	{"no API endpoint", noAPI, "apps", 1, []string{"FAILED", "No API endpoint set. Use 'cf login' or 'cf api' to target an endpoint."}},
+	{"not logged in", loggedOut, "apps", 1, []string{"FAILED", "Not logged in. Use 'cf login' to log in."}},
+	{"credentials rejected", loggedOut, "auth gats-user gats-wrong-password", 1, []string{"FAILED", "Credentials were rejected, please try again."}},
+	{"no org or space targeted", noTarget, "apps", 1, []string{"FAILED", "No org and space targeted, use 'cf target -o ORG -s SPACE' to target an org and space"}},
+	{"org not found", targeted, "org gats-missing-org", 1, []string{"FAILED", "Organization gats-missing-org not found"}},
+	{"space not found", targeted, "space gats-missing-space", 1, []string{"FAILED", "Space gats-missing-space not found"}},
+	{"app not found", targeted, "app gats-missing-app", 1, []string{"FAILED", "App gats-missing-app not found"}},
+	{"not authorized", failing("POST", "/v2/spaces", standin.CCErrorFault(http.StatusForbidden, 10003, "CF-NotAuthorized", "You are not authorized to perform the requested action")), "create-space gats-new-space", 1, []string{"FAILED", "Server error, status code: 403, error code: 10003, message: You are not authorized to perform the requested action"}},
+	{"quota exceeded", failing("PUT", "/v2/apps/"+appGuid, standin.CCErrorFault(http.StatusBadRequest, 100005, "CF-AppMemoryQuotaExceeded", "You have exceeded your organization's memory limit: app requested more memory than available")), "scale gats-app -i 20", 1, []string{"Scaling app gats-app", "FAILED", "Server error, status code: 400, error code: 100005, message: You have exceeded your organization's memory limit: app requested more memory than available"}},
+	{"API unreachable", unreachable, "apps", 1, []string{"FAILED", "Error performing request", "connection refused"}},
+}
+
+// noAPI leaves CF_HOME without a config.
+func noAPI(server *standin.Server, cfHome string) {}
+
+// loggedOut targets the stand-in's API without logging in, like `cf api`.
+func loggedOut(server *standin.Server, cfHome string) {
+	Expect(gatsHelpers.WriteCfConfig(cfHome, gatsHelpers.CfConfigData{
+		Target:                server.URL(),
+		APIVersion:            standin.APIVersion,
+		AuthorizationEndpoint: server.URL(),
+		UaaEndpoint:           server.URL(),
+		AsyncTimeout:          5,
+	})).To(Succeed())
+}
+
+// noTarget logs in without targeting an org or space.
+func noTarget(server *standin.Server, cfHome string) {
+	targeted(server, cfHome)
+
+	config, err := gatsHelpers.ReadCfConfig(cfHome)
+	Expect(err).NotTo(HaveOccurred())
+	config.OrganizationFields = gatsHelpers.CfOrganizationFields{}
+	config.SpaceFields = gatsHelpers.CfSpaceFields{}
+	Expect(gatsHelpers.WriteCfConfig(cfHome, config)).To(Succeed())
+}
+
+// targeted logs in and targets the stand-in's space, which holds appName.
+func targeted(server *standin.Server, cfHome string) {
+	Expect(server.SeedCfHome(cfHome)).To(Succeed())
+
+	server.Handle("GET", "/v2/spaces/"+standin.SpaceGuid+"/apps", standin.Paginated([]interface{}{
+		standin.Resource(appGuid, map[string]interface{}{
+			"name":       appName,
+			"space_guid": standin.SpaceGuid,
+			"state":      "STARTED",
+			"instances":  1,
+			"memory":     256,
+			"disk_quota": 1024,
+		}),
+	}, 50))
+}
+
+// unreachable logs in and targets an API nothing listens on.
+func unreachable(server *standin.Server, cfHome string) {
+	targeted(server, cfHome)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	config, err := gatsHelpers.ReadCfConfig(cfHome)
+	Expect(err).NotTo(HaveOccurred())
+	config.Target = closedURL
+	config.AuthorizationEndpoint = closedURL
+	config.UaaEndpoint = closedURL
+	Expect(gatsHelpers.WriteCfConfig(cfHome, config)).To(Succeed())
+}
+
+// failing is targeted, with fault injected into method and path.
+func failing(method, path string, fault standin.Fault) state {
+	return func(server *standin.Server, cfHome string) {
+		targeted(server, cfHome)
+		server.Inject(method, path, fault)
+	}
+}
+
+var _ = Describe("exit codes and output streams", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-exitcodes")
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	for _, s := range scenarios {
+		s := s
+
+		It(fmt.Sprintf("exits %d with its output on stdout for %s (`cf %s`)", s.exitCode, s.description, s.args), func() {
+			s.state(server, cfHome)
+
+			session := Cf(strings.Fields(s.args)...)
+			Eventually(session, commandTimeout).Should(Exit(s.exitCode))
+
+			output := string(session.Out.Contents())
+			gatsHelpers.ExpectLinesInOrder(output, s.lines)
+			Expect(session.Err.Contents()).To(BeEmpty(), "cf wrote to stderr")
+
+			if s.exitCode == 0 {
+				Expect(output).NotTo(ContainSubstring("FAILED"))
+			}
+		})
+	}
+})
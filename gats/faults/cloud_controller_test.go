@@ -0,0 +1,266 @@
+package faults_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 1 * time.Minute
+
+	// hang is how long HangFault keeps a request waiting. The CLI only has a
+	// dial timeout, so it waits out every hang.
+	hang = 2 * time.Second
+
+	// requestAttempts is how often the CLI's gateway tries a request that
+	// got no response at all.
+	requestAttempts = 3
+)
+
+// panicSignatures mark output from cf/panicprinter or an unrecovered panic.
+var panicSignatures = []string{"Something unexpected happened", "panic:", "goroutine "}
+
+// command is a cf invocation and the route it fails on.
+type command struct {
+	args   []string
+	method string
+	path   string
+}
+
+var commands = []command{
+	{args: []string{"apps"}, method: "GET", path: "/v2/spaces/" + standin.SpaceGuid + "/summary"},
+	{args: []string{"orgs"}, method: "GET", path: "/v2/organizations"},
+	{args: []string{"spaces"}, method: "GET", path: "/v2/organizations/" + standin.OrgGuid + "/spaces"},
+	{args: []string{"create-space", "gats-fault-space"}, method: "POST", path: "/v2/spaces"},
+}
+
+// fault is a way the Cloud Controller misbehaves and what the CLI says.
+type fault struct {
+	description string
+	fault       standin.Fault
+
+	// output is every line the CLI must print, in order.
+	output []string
+
+	// getOnly faults need a real response to mangle, which the stand-in
+	// only has for GETs.
+	getOnly bool
+
+	// retried faults get no response, so the CLI tries requestAttempts times.
+	retried bool
+}
+
+var faults = []fault{
+	{
+		description: "500 with a Cloud Controller error",
+		fault:       standin.CCErrorFault(http.StatusInternalServerError, 10001, "CF-ServerError", "An unknown error occurred."),
+		output:      []string{"FAILED", "Server error, status code: 500, error code: 10001, message: An unknown error occurred."},
+	},
+	{
+		description: "502 with a router's HTML page",
+		fault:       standin.StatusFault(http.StatusBadGateway, "text/html", "<html><body><h1>502 Bad Gateway</h1></body></html>"),
+		output:      []string{"FAILED", "Server error, status code: 502, error code: 0, message: "},
+	},
+	{
+		description: "503 with an empty body",
+		fault:       standin.StatusFault(http.StatusServiceUnavailable, "", ""),
+		output:      []string{"FAILED", "Server error, status code: 503, error code: 0, message: "},
+	},
+	{
+		description: "429 rate limiting",
+		fault:       standin.CCErrorFault(http.StatusTooManyRequests, 10013, "CF-RateLimitExceeded", "Rate Limit Exceeded"),
+		output:      []string{"FAILED", "Server error, status code: 429, error code: 10013, message: Rate Limit Exceeded"},
+	},
+	{
+		description: "403 NotAuthorized (10003)",
+		fault:       standin.CCErrorFault(http.StatusForbidden, 10003, "CF-NotAuthorized", "You are not authorized to perform the requested action"),
+		output:      []string{"FAILED", "Server error, status code: 403, error code: 10003, message: You are not authorized to perform the requested action"},
+	},
+	{
+		description: "400 BadQueryParameter (10005)",
+		fault:       standin.CCErrorFault(http.StatusBadRequest, 10005, "CF-BadQueryParameter", "The query parameter is invalid: q"),
+		output:      []string{"FAILED", "Server error, status code: 400, error code: 10005, message: The query parameter is invalid: q"},
+	},
+	{
+		description: "400 MessageParseError (1001)",
+		fault:       standin.CCErrorFault(http.StatusBadRequest, 1001, "CF-MessageParseError", "Request invalid due to parse error"),
+		output:      []string{"FAILED", "Server error, status code: 400, error code: 1001, message: Request invalid due to parse error"},
+	},
+	{
+		description: "truncated JSON",
+		fault:       standin.TruncatedFault(),
+		output:      []string{"FAILED", "Invalid JSON response from server"},
+		getOnly:     true,
+	},
+	{
+		description: "a reset connection",
+		fault:       standin.ResetFault(),
+		output:      []string{"FAILED", "Error performing request"},
+		retried:     true,
+	},
+	{
+		description: "a hang past the dial timeout",
+		fault:       standin.HangFault(hang),
+		output:      []string{"FAILED", "Error performing request"},
+		retried:     true,
+	},
+}
+
+func expectNoPanic(session *Session) {
+	output := string(session.Out.Contents()) + string(session.Err.Contents())
+	for _, signature := range panicSignatures {
+		Expect(output).NotTo(ContainSubstring(signature))
+	}
+}
+
+func attempts(server *standin.Server, method, path string) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Method == method && request.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+var _ = Describe("a misbehaving Cloud Controller", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-faults")
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	for _, f := range faults {
+		f := f
+
+		for _, c := range commands {
+			c := c
+			if f.getOnly && c.method != "GET" {
+				continue
+			}
+
+			It(fmt.Sprintf("fails `cf %s` cleanly on %s", strings.Join(c.args, " "), f.description), func() {
+				server.Inject(c.method, c.path, f.fault)
+
+				started := time.Now()
+				session := Cf(c.args...)
+				Eventually(session, commandTimeout).Should(Exit(1))
+
+				gatsHelpers.ExpectLinesInOrder(string(session.Out.Contents()), f.output)
+				expectNoPanic(session)
+
+				switch {
+				case f.retried && c.method == "GET":
+					Expect(attempts(server, c.method, c.path)).To(BeNumerically(">=", requestAttempts))
+				case f.retried:
+					// The gateway retries with the request body it already
+					// sent, so later attempts may fail before reaching the
+					// server.
+					Expect(attempts(server, c.method, c.path)).To(BeNumerically(">=", 1))
+				default:
+					Expect(attempts(server, c.method, c.path)).To(Equal(1))
+				}
+				fmt.Fprintf(GinkgoWriter, "cf %s failed after %s\n", strings.Join(c.args, " "), time.Since(started))
+			})
+		}
+	}
+
+	It("waits out every hang instead of timing out the read", func() {
+		server.Inject("GET", commands[0].path, standin.HangFault(hang))
+
+		started := time.Now()
+		Eventually(Cf(commands[0].args...), commandTimeout).Should(Exit(1))
+		Expect(time.Since(started)).To(BeNumerically(">=", requestAttempts*hang))
+	})
+
+	It("recovers when only the first attempt is dropped", func() {
+		server.Inject("GET", commands[0].path, standin.ResetFault(), 1)
+
+		session := Cf(commands[0].args...)
+		Eventually(session, commandTimeout).Should(Exit(0))
+		expectNoPanic(session)
+		Expect(attempts(server, "GET", commands[0].path)).To(Equal(2))
+	})
+
+	It("doesn't retry a 503", func() {
+		server.Inject("GET", commands[0].path, standin.StatusFault(http.StatusServiceUnavailable, "", ""), 1)
+
+		Eventually(Cf(commands[0].args...), commandTimeout).Should(Exit(1))
+		Expect(attempts(server, "GET", commands[0].path)).To(Equal(1))
+	})
+})
+
+var _ = Describe("a misbehaving UAA", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-faults")
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	It("reports a UAA 500 on login as an unreachable endpoint", func() {
+		server.Inject("POST", "/oauth/token", standin.UAAErrorFault(http.StatusInternalServerError, "server_error", "Internal error"))
+
+		session := CfAuth(standin.Username, standin.Password)
+		Eventually(session, commandTimeout).Should(Exit(1))
+		gatsHelpers.ExpectLinesInOrder(string(session.Out.Contents()), []string{"FAILED", "The targeted API endpoint could not be reached."})
+		expectNoPanic(session)
+	})
+
+	It("reports a UAA 503 while refreshing an expired token", func() {
+		server.ExpireAccessTokens()
+		server.Inject("POST", "/oauth/token", standin.StatusFault(http.StatusServiceUnavailable, "text/html", "<html>Service Unavailable</html>"))
+
+		session := Cf(commands[0].args...)
+		Eventually(session, commandTimeout).Should(Exit(1))
+		gatsHelpers.ExpectLinesInOrder(string(session.Out.Contents()), []string{"FAILED", "status code: 503"})
+		expectNoPanic(session)
+	})
+
+	It("reports truncated token JSON while refreshing an expired token", func() {
+		server.ExpireAccessTokens()
+		server.Inject("POST", "/oauth/token", standin.TruncatedFault())
+
+		session := Cf(commands[0].args...)
+		Eventually(session, commandTimeout).Should(Exit(1))
+		gatsHelpers.ExpectLinesInOrder(string(session.Out.Contents()), []string{"FAILED", "auth request failed", "Invalid JSON response from server"})
+		expectNoPanic(session)
+	})
+})
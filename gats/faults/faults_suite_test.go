@@ -0,0 +1,11 @@
+package faults_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	"testing"
+)
+
+func TestFaults(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Faults Suite", "faults")
+}
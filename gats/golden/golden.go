@@ -0,0 +1,115 @@
+package golden
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"github.com/onsi/gomega/types"
+)
+
+// UpdateEnvVar makes golden comparisons rewrite the golden files with the
+// actual output instead of failing.
+const UpdateEnvVar = "GATS_UPDATE_GOLDEN"
+
+// diffContext is how many unchanged lines surround each change in a diff.
+const diffContext = 3
+
+// Updating reports whether golden files are being regenerated.
+func Updating() bool {
+	return os.Getenv(UpdateEnvVar) != ""
+}
+
+// Check compares actual with the golden file at path and returns a unified
+// diff from the file to actual, empty when they match. When updating, it
+// writes actual to path instead.
+func Check(path, actual string) (string, error) {
+	if Updating() {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return "", err
+		}
+		return "", ioutil.WriteFile(path, []byte(actual), 0644)
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no golden file at %s; run with %s=true to create it", path, UpdateEnvVar)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return gatsHelpers.UnifiedDiff(string(expected), actual, diffContext), nil
+}
+
+// Report describes a mismatch with the golden file at path.
+func Report(path, diff string) string {
+	return fmt.Sprintf("output differs from %s (run with %s=true to accept it):\n--- %s\n+++ actual\n%s", path, UpdateEnvVar, path, diff)
+}
+
+// MatchGoldenFile succeeds when the actual string matches the golden file at
+// path, or always when updating.
+func MatchGoldenFile(path string) types.GomegaMatcher {
+	return &goldenFileMatcher{path: path}
+}
+
+type goldenFileMatcher struct {
+	path string
+	diff string
+}
+
+func (m *goldenFileMatcher) Match(actual interface{}) (bool, error) {
+	text, ok := actual.(string)
+	if !ok {
+		return false, fmt.Errorf("MatchGoldenFile expects a string, got %T", actual)
+	}
+
+	diff, err := Check(m.path, text)
+	if err != nil {
+		return false, err
+	}
+
+	m.diff = diff
+	return diff == "", nil
+}
+
+func (m *goldenFileMatcher) FailureMessage(actual interface{}) string {
+	return Report(m.path, m.diff)
+}
+
+func (m *goldenFileMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("expected output not to match %s", m.path)
+}
+
+// Failures collects the mismatches of a spec that checks several outputs
+// against golden files, so that it reports all of them at once.
+type Failures struct {
+	messages []string
+}
+
+// Check compares actual with the golden file at path and records a mismatch.
+func (f *Failures) Check(path, actual string) {
+	diff, err := Check(path, actual)
+	switch {
+	case err != nil:
+		f.Add(err.Error())
+	case diff != "":
+		f.Add(Report(path, diff))
+	}
+}
+
+func (f *Failures) Add(message string) {
+	f.messages = append(f.messages, message)
+}
+
+func (f *Failures) Len() int {
+	return len(f.messages)
+}
+
+func (f *Failures) String() string {
+	return strings.Join(f.messages, "\n\n")
+}
@@ -0,0 +1,68 @@
+package golden_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/golden"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("golden files", func() {
+	var (
+		dir  string
+		path string
+
+		originalUpdate string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gats-golden")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "nested", "apps.txt")
+
+		originalUpdate = os.Getenv(UpdateEnvVar)
+		os.Setenv(UpdateEnvVar, "")
+	})
+
+	AfterEach(func() {
+		os.Setenv(UpdateEnvVar, originalUpdate)
+		os.RemoveAll(dir)
+	})
+
+	It("asks for an update when the golden file is missing", func() {
+		_, err := Check(path, "OK\n")
+		Expect(err).To(MatchError(ContainSubstring(UpdateEnvVar + "=true")))
+	})
+
+	It("writes the golden file when updating", func() {
+		os.Setenv(UpdateEnvVar, "true")
+		Expect("OK\n").To(MatchGoldenFile(path))
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("OK\n"))
+	})
+
+	It("diffs the actual output against the golden file", func() {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte("name    state\napp-1   started\n"), 0644)).To(Succeed())
+
+		Expect("name    state\napp-1   started\n").To(MatchGoldenFile(path))
+
+		matcher := MatchGoldenFile(path)
+		Expect(matcher.Match("name     state\napp-1    started\n")).To(BeFalse())
+		Expect(matcher.FailureMessage(nil)).To(ContainSubstring(
+			"--- " + path + "\n+++ actual\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-name    state\n" +
+				"-app-1   started\n" +
+				"+name     state\n" +
+				"+app-1    started\n",
+		))
+	})
+})
@@ -0,0 +1,85 @@
+package golden
+
+import (
+	"regexp"
+	"strings"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+const (
+	UsagePlaceholder    = "{{usage}}"
+	PercentPlaceholder  = "{{percent}}"
+	DurationPlaceholder = "{{duration}}"
+)
+
+var (
+	// usagePattern matches the amount used in the "24.5M of 256M" memory
+	// and disk columns of `cf app`, keeping the limit.
+	usagePattern = regexp.MustCompile(`\b\d+(\.\d+)?[KMGT]?B? of\b`)
+
+	percentPattern = regexp.MustCompile(`\b\d+(\.\d+)?%`)
+
+	// durationPattern matches time.Duration strings such as 1m30.5s or 250ms.
+	durationPattern = regexp.MustCompile(`\b(\d+h)?(\d+m)?\d+(\.\d+)?(ns|µs|us|ms|s)\b`)
+
+	// clockTimePattern matches the 12-hour times `cf app` prints in its
+	// "since" column.
+	clockTimePattern = regexp.MustCompile(regexp.QuoteMeta(gatsHelpers.NormalizedTimestamp) + ` [AP]M\b`)
+
+	// columnGapPattern matches the padding between columns, not indentation.
+	columnGapPattern = regexp.MustCompile(`(\S)[ \t]{2,}`)
+)
+
+type namedValue struct {
+	value       string
+	placeholder string
+}
+
+// Normalizer prepares human cf output for a golden file. On top of
+// helpers.OutputNormalizer's GUIDs, timestamps and version banner, it replaces
+// the names given to Name, usage figures, percentages and durations with
+// placeholders. Column padding follows the widest value in a column, so the
+// gaps between columns collapse to two spaces and trailing spaces go.
+type Normalizer struct {
+	output *gatsHelpers.OutputNormalizer
+	names  []namedValue
+}
+
+func NewNormalizer() *Normalizer {
+	return &Normalizer{output: gatsHelpers.NewOutputNormalizer()}
+}
+
+// Name makes the normalizer replace value, e.g. a random app name or the API
+// endpoint, with placeholder.
+func (n *Normalizer) Name(value, placeholder string) {
+	if value == "" {
+		return
+	}
+
+	// Longer values go first, so an app name doesn't break up the route
+	// containing it.
+	i := 0
+	for i < len(n.names) && len(n.names[i].value) >= len(value) {
+		i++
+	}
+	n.names = append(n.names[:i], append([]namedValue{{value: value, placeholder: placeholder}}, n.names[i:]...)...)
+}
+
+func (n *Normalizer) Normalize(text string) string {
+	for _, name := range n.names {
+		text = strings.Replace(text, name.value, name.placeholder, -1)
+	}
+
+	text = n.output.Normalize(text)
+	text = clockTimePattern.ReplaceAllString(text, gatsHelpers.NormalizedTimestamp)
+	text = usagePattern.ReplaceAllString(text, UsagePlaceholder+" of")
+	text = percentPattern.ReplaceAllString(text, PercentPlaceholder)
+	text = durationPattern.ReplaceAllString(text, DurationPlaceholder)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(columnGapPattern.ReplaceAllString(line, "$1  "), " \t")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,36 @@
+package golden_test
+
+import (
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/golden"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalizer", func() {
+	It("replaces names, usage, percentages and durations", func() {
+		normalizer := NewNormalizer()
+		normalizer.Name("GATS-SNAPSHOT-0a1b2c3d-1111-2222-3333-444455556666", "{{app}}")
+		normalizer.Name("bosh-lite.com", "{{apps-domain}}")
+
+		Expect(normalizer.Normalize(
+			"urls: GATS-SNAPSHOT-0a1b2c3d-1111-2222-3333-444455556666.bosh-lite.com\n" +
+				"     state     since                    cpu    memory          disk            details\n" +
+				"#0   running   2016-10-27 10:00:00 AM   0.3%   24.5M of 256M   71.2M of 512M   \n" +
+				"Staging took 1m30.5s\n",
+		)).To(Equal(
+			"urls: {{app}}.{{apps-domain}}\n" +
+				"     state  since  cpu  memory  disk  details\n" +
+				"#0  running  2000-01-01T00:00:00Z  {{percent}}  {{usage}} of 256M  {{usage}} of 512M\n" +
+				"Staging took {{duration}}\n",
+		))
+	})
+
+	It("replaces longer names first", func() {
+		normalizer := NewNormalizer()
+		normalizer.Name("gats-app", "{{app}}")
+		normalizer.Name("gats-app.bosh-lite.com", "{{route}}")
+
+		Expect(normalizer.Normalize("gats-app gats-app.bosh-lite.com")).To(Equal("{{app}} {{route}}"))
+	})
+})
@@ -0,0 +1,29 @@
+package help_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+var pluginPath string
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	path, err := Build("code.cloudfoundry.org/cli-acceptance-tests/gats/plugin/fixtures")
+	Expect(err).NotTo(HaveOccurred())
+	return []byte(path)
+}, func(path []byte) {
+	pluginPath = string(path)
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	CleanupBuildArtifacts()
+})
+
+func TestHelp(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Help Suite", "help")
+}
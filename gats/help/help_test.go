@@ -0,0 +1,221 @@
+package help_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/golden"
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 30 * time.Second
+
+	firstCommandSection  = "GETTING STARTED:"
+	pluginCommandSection = "INSTALLED PLUGIN COMMANDS:"
+)
+
+var (
+	goldenDir = filepath.Join("..", "testdata", "help")
+
+	// helpCommandPattern matches a command line of `cf help -a`: the name,
+	// padded to the longest name, and its description.
+	helpCommandPattern = regexp.MustCompile(`(?m)^   ([a-z][a-z0-9-]*)\s+\S`)
+
+	// helpEnvironment pins everything that changes how help is rendered.
+	helpEnvironment = []string{"CF_COLOR", "LANG", "LC_ALL"}
+)
+
+// gatsPluginCommand is the help a plugin declared for one of its commands in
+// its PluginMetadata.
+type gatsPluginCommand struct {
+	name     string
+	helpText string
+	usage    string
+	options  map[string]string
+}
+
+// helpOutput runs cf and returns its normalized stdout once it exits.
+func helpOutput(args ...string) (string, int) {
+	session := Cf(args...)
+	Eventually(session, commandTimeout).Should(Exit())
+	return gatsHelpers.NewOutputNormalizer().Normalize(string(session.Out.Contents())), session.ExitCode()
+}
+
+// commandsFromHelp lists the core commands in `cf help -a` output, in order.
+func commandsFromHelp(output string) []string {
+	start := strings.Index(output, firstCommandSection)
+	if start < 0 {
+		return nil
+	}
+	output = output[start:]
+	if end := strings.Index(output, pluginCommandSection); end >= 0 {
+		output = output[:end]
+	}
+
+	var commands []string
+	seen := map[string]bool{}
+	for _, match := range helpCommandPattern.FindAllStringSubmatch(output, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			commands = append(commands, match[1])
+		}
+	}
+	return commands
+}
+
+// checkEveryCommand compares help(command) with dir/<command>.txt for every
+// command and fails once with all the differences. Golden files of commands
+// that are gone fail too, or are removed when updating.
+func checkEveryCommand(dir string, commands []string, help func(command string) string) {
+	failures := &golden.Failures{}
+	current := map[string]bool{}
+
+	for _, command := range commands {
+		path := filepath.Join(dir, command+".txt")
+		current[path] = true
+		failures.Check(path, help(command))
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	Expect(err).NotTo(HaveOccurred())
+	for _, path := range paths {
+		if current[path] {
+			continue
+		}
+		if golden.Updating() {
+			Expect(os.Remove(path)).To(Succeed())
+		} else {
+			failures.Add(fmt.Sprintf("%s is for a command `cf help -a` no longer lists", path))
+		}
+	}
+
+	if failures.Len() > 0 {
+		Fail(fmt.Sprintf("%d of %d commands differ from %s:\n\n%s", failures.Len(), len(commands), dir, failures))
+	}
+}
+
+var _ = Describe("help", func() {
+	var (
+		cfHome string
+
+		originalEnvironment map[string]string
+		restoreCfHome       func()
+	)
+
+	BeforeEach(func() {
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-help")
+
+		originalEnvironment = map[string]string{}
+		for _, name := range helpEnvironment {
+			originalEnvironment[name] = os.Getenv(name)
+		}
+
+		os.Setenv("CF_COLOR", "false")
+		os.Setenv("LANG", "en_US.UTF-8")
+		os.Setenv("LC_ALL", "en_US.UTF-8")
+	})
+
+	AfterEach(func() {
+		for name, value := range originalEnvironment {
+			os.Setenv(name, value)
+		}
+		restoreCfHome()
+	})
+
+	Context("for core commands", func() {
+		It("matches the golden `cf help`", func() {
+			output, exitCode := helpOutput("help")
+			Expect(exitCode).To(Equal(0))
+			Expect(output).To(golden.MatchGoldenFile(filepath.Join(goldenDir, "help.txt")))
+		})
+
+		It("matches the golden `cf help -a`", func() {
+			output, exitCode := helpOutput("help", "-a")
+			Expect(exitCode).To(Equal(0))
+			Expect(commandsFromHelp(output)).To(ContainElement("push"))
+			Expect(output).To(golden.MatchGoldenFile(filepath.Join(goldenDir, "help-a.txt")))
+		})
+
+		It("matches the golden `cf help <command>` of every command", func() {
+			all, _ := helpOutput("help", "-a")
+
+			checkEveryCommand(filepath.Join(goldenDir, "help-command"), commandsFromHelp(all), func(command string) string {
+				output, exitCode := helpOutput("help", command)
+				Expect(exitCode).To(Equal(0), "`cf help %s` failed", command)
+				return output
+			})
+		})
+
+		It("matches the golden `cf <command> -h` of every command", func() {
+			all, _ := helpOutput("help", "-a")
+
+			checkEveryCommand(filepath.Join(goldenDir, "command-h"), commandsFromHelp(all), func(command string) string {
+				output, _ := helpOutput(command, "-h")
+				return output
+			})
+		})
+	})
+
+	Context("for plugin commands", func() {
+		var commands []gatsPluginCommand
+
+		BeforeEach(func() {
+			Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+
+			pluginData, err := gatsHelpers.ReadPluginConfig(cfHome)
+			Expect(err).NotTo(HaveOccurred())
+
+			commands = nil
+			for _, metadata := range pluginData.Plugins {
+				for _, command := range metadata.Commands {
+					commands = append(commands, gatsPluginCommand{
+						name:     command.Name,
+						helpText: command.HelpText,
+						usage:    command.UsageDetails.Usage,
+						options:  command.UsageDetails.Options,
+					})
+				}
+			}
+			Expect(commands).NotTo(BeEmpty())
+		})
+
+		It("lists each command with its help text in `cf help -a`", func() {
+			output, exitCode := helpOutput("help", "-a")
+			Expect(exitCode).To(Equal(0))
+
+			section := strings.Index(output, pluginCommandSection)
+			Expect(section).To(BeNumerically(">=", 0), "no %s section in:\n%s", pluginCommandSection, output)
+
+			for _, command := range commands {
+				Expect(output[section:]).To(MatchRegexp(`(?m)^   %s\s+%s$`, regexp.QuoteMeta(command.name), regexp.QuoteMeta(command.helpText)))
+			}
+		})
+
+		It("shows each command's usage details in `cf help <command>`", func() {
+			for _, command := range commands {
+				output, exitCode := helpOutput("help", command.name)
+				Expect(exitCode).To(Equal(0), "`cf help %s` failed", command.name)
+
+				Expect(output).To(ContainSubstring("NAME:\n   %s - %s\n", command.name, command.helpText))
+				Expect(output).To(ContainSubstring("USAGE:\n   %s\n", command.usage))
+
+				if len(command.options) > 0 {
+					Expect(output).To(ContainSubstring("OPTIONS:\n"))
+				}
+				for option, description := range command.options {
+					Expect(output).To(MatchRegexp(`(?m)^   -%s +%s$`, regexp.QuoteMeta(option), regexp.QuoteMeta(description)))
+				}
+			}
+		})
+	})
+})
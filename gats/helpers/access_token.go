@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type AccessTokenClaims struct {
+	UserName  string `json:"user_name"`
+	Email     string `json:"email"`
+	UserGuid  string `json:"user_id"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// UnsignedAccessToken builds a JWT-shaped token that the CLI can decode for
+// user name, email and guid. The signature part is a placeholder; only
+// stand-ins that do not verify tokens should hand it out.
+func UnsignedAccessToken(userName, userGuid string, expiresAt time.Time) string {
+	claims := AccessTokenClaims{
+		UserName:  userName,
+		Email:     userName,
+		UserGuid:  userGuid,
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	return strings.Join([]string{encodeSegment(header), encodeSegment(payload), "gats"}, ".")
+}
+
+func encodeSegment(segment []byte) string {
+	return strings.TrimRight(base64.StdEncoding.EncodeToString(segment), "=")
+}
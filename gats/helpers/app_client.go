@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	AppRequestTimeout = 30 * time.Second
+	RoutePropagation  = 2 * time.Minute
+)
+
+type AppRequest struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    string
+	Timeout time.Duration
+}
+
+type AppResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// AppClient talks to pushed apps over net/http instead of shelling out to curl.
+type AppClient struct {
+	config    Config
+	transport *http.Transport
+
+	// RetryTimeout bounds how long a request is retried while the gorouter
+	// answers 404 or 502 because the app's route has not propagated yet.
+	RetryTimeout  time.Duration
+	RetryInterval time.Duration
+}
+
+func NewAppClient(config Config) *AppClient {
+	return &AppClient{
+		config: config,
+		transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipSSLValidation},
+		},
+		RetryTimeout:  RoutePropagation,
+		RetryInterval: 2 * time.Second,
+	}
+}
+
+// AppURL builds the URL of path on an app's default route.
+func (c *AppClient) AppURL(appName, path string) string {
+	return c.config.Protocol() + appName + "." + c.config.AppsDomain + path
+}
+
+// Do sends request to appName, retrying while the route is still propagating.
+func (c *AppClient) Do(appName string, request AppRequest) (AppResponse, error) {
+	return c.DoURL(c.AppURL(appName, request.Path), request)
+}
+
+// DoURL sends request to url, retrying while the route is still propagating.
+// Any other failure, such as a refused connection or the request timing out,
+// is returned straight away.
+func (c *AppClient) DoURL(url string, request AppRequest) (AppResponse, error) {
+	deadline := time.Now().Add(c.RetryTimeout)
+
+	for {
+		response, err := c.doOnce(url, request)
+		if err != nil || !isRoutePropagating(response) || time.Now().After(deadline) {
+			return response, err
+		}
+
+		time.Sleep(c.RetryInterval)
+	}
+}
+
+func (c *AppClient) doOnce(url string, request AppRequest) (AppResponse, error) {
+	method := request.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	timeout := request.Timeout
+	if timeout == 0 {
+		timeout = AppRequestTimeout
+	}
+
+	var body io.Reader
+	if request.Body != "" {
+		body = strings.NewReader(request.Body)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return AppResponse{}, err
+	}
+	for key, values := range request.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	client := &http.Client{Transport: c.transport, Timeout: timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return AppResponse{}, err
+	}
+	defer res.Body.Close()
+
+	contents, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return AppResponse{}, err
+	}
+
+	return AppResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       string(contents),
+	}, nil
+}
+
+func isRoutePropagating(response AppResponse) bool {
+	return response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusBadGateway
+}
@@ -0,0 +1,79 @@
+package helpers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppClient", func() {
+	var (
+		client   *AppClient
+		server   *httptest.Server
+		attempts int
+	)
+
+	BeforeEach(func() {
+		attempts = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("X-Method", r.Method)
+			w.Header().Set("X-Echo", r.Header.Get("X-Echo"))
+			w.Write([]byte("Hi, I'm Dora!"))
+		}))
+
+		config := Config{}
+		config.AppsDomain = "bosh-lite.com"
+		config.UseHttp = true
+		client = NewAppClient(config)
+		client.RetryInterval = time.Millisecond
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("builds app URLs from the apps domain and protocol", func() {
+		Expect(client.AppURL("dora", "/env")).To(Equal("http://dora.bosh-lite.com/env"))
+	})
+
+	It("retries while the route is propagating and returns a typed response", func() {
+		response, err := client.DoURL(server.URL, AppRequest{
+			Method: "PUT",
+			Header: http.Header{"X-Echo": {"hello"}},
+			Body:   "data",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attempts).To(Equal(3))
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(response.Header.Get("X-Method")).To(Equal("PUT"))
+		Expect(response.Header.Get("X-Echo")).To(Equal("hello"))
+		Expect(response.Body).To(Equal("Hi, I'm Dora!"))
+	})
+
+	It("doesn't retry requests that fail for other reasons", func() {
+		server.Close()
+
+		start := time.Now()
+		_, err := client.DoURL(server.URL, AppRequest{})
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", client.RetryTimeout))
+	})
+
+	It("gives up once the retry timeout has passed", func() {
+		client.RetryTimeout = 0
+		response, err := client.DoURL(server.URL, AppRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
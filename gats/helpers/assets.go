@@ -1,5 +1,7 @@
 package helpers
 
+import "path/filepath"
+
 type Assets struct {
 	ServiceBroker      string
 	SecurityRules      string
@@ -8,10 +10,15 @@ type Assets struct {
 }
 
 func NewAssets() Assets {
+	return AssetsIn("..")
+}
+
+// AssetsIn locates the assets relative to the gats directory.
+func AssetsIn(gatsDirectory string) Assets {
 	return Assets{
-		ServiceBroker:      "../assets/service_broker",
-		SecurityRules:      "../assets/security_groups/security-rules.json",
-		EmptySecurityRules: "../assets/security_groups/empty-security-rules.json",
-		DoraApp:            "../assets/dora",
+		ServiceBroker:      filepath.Join(gatsDirectory, "assets", "service_broker"),
+		SecurityRules:      filepath.Join(gatsDirectory, "assets", "security_groups", "security-rules.json"),
+		EmptySecurityRules: filepath.Join(gatsDirectory, "assets", "security_groups", "empty-security-rules.json"),
+		DoraApp:            filepath.Join(gatsDirectory, "assets", "dora"),
 	}
 }
@@ -0,0 +1,203 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type Capability string
+
+const (
+	RoutingAPI  Capability = "a routing API"
+	Loggregator Capability = "a loggregator endpoint"
+	Doppler     Capability = "a doppler endpoint"
+	Docker      Capability = "docker support"
+	Tasks       Capability = "tasks (v3)"
+	SSH         Capability = "app SSH"
+	AppPorts    Capability = "app ports"
+)
+
+// appPortsMinimumAPIVersion is the first CC API version that accepts
+// `ports` on apps.
+const appPortsMinimumAPIVersion = "2.51.0"
+
+// Capabilities describes what the targeted foundation supports, as worked
+// out from /v2/info and the feature flags rather than hand-set config flags.
+type Capabilities struct {
+	APIVersion          string
+	RoutingAPIEndpoint  string
+	LoggregatorEndpoint string
+	DopplerEndpoint     string
+	SSHEndpoint         string
+	DockerEnabled       bool
+	TasksEnabled        bool
+	AppPortsSupported   bool
+}
+
+type capabilitiesInfo struct {
+	APIVersion             string `json:"api_version"`
+	RoutingEndpoint        string `json:"routing_endpoint"`
+	LoggingEndpoint        string `json:"logging_endpoint"`
+	DopplerLoggingEndpoint string `json:"doppler_logging_endpoint"`
+	AppSSHEndpoint         string `json:"app_ssh_endpoint"`
+}
+
+type featureFlag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ParseCapabilities works out the capabilities from the bodies of
+// GET /v2/info and GET /v2/config/feature_flags.
+func ParseCapabilities(info, featureFlags []byte) (Capabilities, error) {
+	var parsedInfo capabilitiesInfo
+	err := json.Unmarshal(info, &parsedInfo)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("parsing /v2/info: %s", err)
+	}
+
+	var flags []featureFlag
+	err = json.Unmarshal(featureFlags, &flags)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("parsing /v2/config/feature_flags: %s", err)
+	}
+
+	capabilities := Capabilities{
+		APIVersion:          parsedInfo.APIVersion,
+		RoutingAPIEndpoint:  parsedInfo.RoutingEndpoint,
+		LoggregatorEndpoint: parsedInfo.LoggingEndpoint,
+		DopplerEndpoint:     parsedInfo.DopplerLoggingEndpoint,
+		SSHEndpoint:         parsedInfo.AppSSHEndpoint,
+	}
+
+	// Older CCs don't advertise doppler; the cf CLI derives it the same way.
+	if capabilities.DopplerEndpoint == "" && strings.Contains(parsedInfo.LoggingEndpoint, "loggregator") {
+		capabilities.DopplerEndpoint = strings.Replace(parsedInfo.LoggingEndpoint, "loggregator", "doppler", 1)
+	}
+
+	for _, flag := range flags {
+		switch flag.Name {
+		case "diego_docker":
+			capabilities.DockerEnabled = flag.Enabled
+		case "task_creation":
+			capabilities.TasksEnabled = flag.Enabled
+		}
+	}
+
+	apiVersion, err := semver.Make(parsedInfo.APIVersion)
+	if err == nil {
+		capabilities.AppPortsSupported = apiVersion.GTE(semver.MustParse(appPortsMinimumAPIVersion))
+	}
+
+	return capabilities, nil
+}
+
+func (c Capabilities) Has(capability Capability) bool {
+	switch capability {
+	case RoutingAPI:
+		return c.RoutingAPIEndpoint != ""
+	case Loggregator:
+		return c.LoggregatorEndpoint != ""
+	case Doppler:
+		return c.DopplerEndpoint != ""
+	case Docker:
+		return c.DockerEnabled
+	case Tasks:
+		return c.TasksEnabled
+	case SSH:
+		return c.SSHEndpoint != ""
+	case AppPorts:
+		return c.AppPortsSupported
+	}
+	return false
+}
+
+// Missing lists the capabilities out of required the foundation lacks.
+func (c Capabilities) Missing(required ...Capability) []Capability {
+	var missing []Capability
+	for _, capability := range required {
+		if !c.Has(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
+
+var (
+	detectedCapabilities     Capabilities
+	detectCapabilitiesOnce   sync.Once
+	detectCapabilitiesFailed error
+)
+
+// DetectCapabilities probes the targeted foundation as the admin user. The
+// probe runs once per suite process; later calls return the cached result,
+// and fail the same way when the probe failed.
+func DetectCapabilities() Capabilities {
+	detectCapabilitiesOnce.Do(func() {
+		// Stands until the probe finishes, so a probe that fails part way,
+		// e.g. at the `cf logout` AsUser ends with, can't leave empty
+		// capabilities that skip every spec.
+		detectCapabilitiesFailed = errors.New("probing the foundation's capabilities did not finish")
+
+		config := LoadConfig()
+		timeout := config.ScaledTimeout(1 * time.Minute)
+
+		var info, featureFlags []byte
+		var err error
+		cf.AsUser(adminUserContext(config), timeout, func() {
+			info, err = cfCurl("/v2/info", timeout)
+			if err == nil {
+				featureFlags, err = cfCurl("/v2/config/feature_flags", timeout)
+			}
+		})
+		if err != nil {
+			detectCapabilitiesFailed = err
+			return
+		}
+
+		detectedCapabilities, detectCapabilitiesFailed = ParseCapabilities(info, featureFlags)
+	})
+
+	ExpectWithOffset(1, detectCapabilitiesFailed).NotTo(HaveOccurred())
+	return detectedCapabilities
+}
+
+// RequireCapabilities skips the current spec unless the foundation has every
+// one of required.
+func RequireCapabilities(required ...Capability) {
+	missing := DetectCapabilities().Missing(required...)
+	if len(missing) == 0 {
+		return
+	}
+
+	names := make([]string, len(missing))
+	for i, capability := range missing {
+		names[i] = string(capability)
+	}
+	Skip("requires " + strings.Join(names, " and "))
+}
+
+func cfCurl(endpoint string, timeout time.Duration) ([]byte, error) {
+	session := cf.Cf("curl", endpoint)
+
+	select {
+	case <-session.Exited:
+	case <-time.After(timeout):
+		session.Kill()
+		return nil, fmt.Errorf("`cf curl %s` did not finish within %s", endpoint, timeout)
+	}
+
+	if session.ExitCode() != 0 {
+		return nil, fmt.Errorf("`cf curl %s` exited %d:\n%s", endpoint, session.ExitCode(), session.Out.Contents())
+	}
+	return session.Out.Contents(), nil
+}
@@ -0,0 +1,93 @@
+package helpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Capabilities", func() {
+	It("works out capabilities from /v2/info and the feature flags", func() {
+		capabilities, err := ParseCapabilities([]byte(`{
+			"api_version": "2.54.0",
+			"routing_endpoint": "https://api.bosh-lite.com/routing",
+			"logging_endpoint": "wss://loggregator.bosh-lite.com:443",
+			"app_ssh_endpoint": "ssh.bosh-lite.com:2222"
+		}`), []byte(`[
+			{"name": "diego_docker", "enabled": true},
+			{"name": "task_creation", "enabled": false}
+		]`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(capabilities.APIVersion).To(Equal("2.54.0"))
+		Expect(capabilities.DopplerEndpoint).To(Equal("wss://doppler.bosh-lite.com:443"))
+		Expect(capabilities.Missing(RoutingAPI, Loggregator, Doppler, Docker, Tasks, SSH, AppPorts)).To(Equal([]Capability{Tasks}))
+	})
+
+	It("reports a foundation without optional components", func() {
+		capabilities, err := ParseCapabilities([]byte(`{"api_version": "2.48.0"}`), []byte(`[]`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(capabilities.Has(RoutingAPI)).To(BeFalse())
+		Expect(capabilities.Has(Doppler)).To(BeFalse())
+		Expect(capabilities.Has(SSH)).To(BeFalse())
+		Expect(capabilities.Has(AppPorts)).To(BeFalse())
+	})
+
+	It("fails on a body that isn't JSON", func() {
+		_, err := ParseCapabilities([]byte(`<html>`), []byte(`[]`))
+		Expect(err).To(MatchError(ContainSubstring("/v2/info")))
+	})
+})
+
+var _ = Describe("DetectCapabilities", func() {
+	// It probes once per process, so this is the only spec that calls it.
+	It("keeps failing after a probe that failed", func() {
+		if runtime.GOOS == "windows" {
+			Skip("fakes cf with a shell script")
+		}
+
+		directory, err := ioutil.TempDir("", "gats-capabilities")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(directory)
+
+		// A cf that logs in but can't reach the API.
+		script := "#!/bin/sh\n[ \"$1\" = curl ] && echo 'gats probe refused' && exit 1\nexit 0\n"
+		Expect(ioutil.WriteFile(filepath.Join(directory, "cf"), []byte(script), 0755)).To(Succeed())
+		configPath := filepath.Join(directory, "gats_config.json")
+		Expect(ioutil.WriteFile(configPath, []byte(`{"api": "api.example.com", "admin_user": "admin", "admin_password": "gats-capabilities-secret", "timeout_scale": 1}`), 0600)).To(Succeed())
+
+		for name, value := range map[string]string{
+			"PATH":    directory + string(os.PathListSeparator) + os.Getenv("PATH"),
+			"CONFIG":  configPath,
+			"CF_HOME": os.Getenv("CF_HOME"),
+		} {
+			defer os.Setenv(name, os.Getenv(name))
+			os.Setenv(name, value)
+		}
+
+		detect := func() (failure string) {
+			defer RegisterFailHandler(Fail)
+			defer func() { recover() }()
+			RegisterFailHandler(func(message string, _ ...int) {
+				failure = message
+				panic(message)
+			})
+
+			DetectCapabilities()
+			return ""
+		}
+
+		first := detect()
+		Expect(first).To(ContainSubstring("`cf curl /v2/info` exited 1"))
+		Expect(first).To(ContainSubstring("gats probe refused"))
+
+		Expect(detect()).To(ContainSubstring("`cf curl /v2/info` exited 1"))
+	})
+})
@@ -0,0 +1,216 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/cloudfoundry/cli/cf/configuration/pluginconfig"
+	. "github.com/onsi/gomega"
+)
+
+// CfConfigData mirrors coreconfig.Data, the layout of $CF_HOME/.cf/config.json.
+// coreconfig itself can't be imported: it pulls in cf/i18n, whose go-i18n
+// dependency isn't vendored here.
+type CfConfigData struct {
+	ConfigVersion            int
+	Target                   string
+	APIVersion               string
+	AuthorizationEndpoint    string
+	LoggregatorEndPoint      string
+	DopplerEndPoint          string
+	UaaEndpoint              string
+	RoutingAPIEndpoint       string
+	AccessToken              string
+	SSHOAuthClient           string
+	RefreshToken             string
+	OrganizationFields       CfOrganizationFields
+	SpaceFields              CfSpaceFields
+	SSLDisabled              bool
+	AsyncTimeout             uint
+	Trace                    string
+	ColorEnabled             string
+	Locale                   string
+	PluginRepos              []CfPluginRepo
+	MinCLIVersion            string
+	MinRecommendedCLIVersion string
+}
+
+// CfOrganizationFields mirrors models.OrganizationFields.
+type CfOrganizationFields struct {
+	GUID            string
+	Name            string
+	QuotaDefinition CfQuotaFields
+}
+
+// CfQuotaFields mirrors models.QuotaFields.
+type CfQuotaFields struct {
+	GUID                    string      `json:"guid,omitempty"`
+	Name                    string      `json:"name"`
+	MemoryLimit             int64       `json:"memory_limit"`
+	InstanceMemoryLimit     int64       `json:"instance_memory_limit"`
+	RoutesLimit             int         `json:"total_routes"`
+	ServicesLimit           int         `json:"total_services"`
+	NonBasicServicesAllowed bool        `json:"non_basic_services_allowed"`
+	AppInstanceLimit        int         `json:"app_instance_limit"`
+	ReservedRoutePorts      json.Number `json:"total_reserved_route_ports,omitempty"`
+}
+
+// CfSpaceFields mirrors models.SpaceFields.
+type CfSpaceFields struct {
+	GUID     string
+	Name     string
+	AllowSSH bool
+}
+
+// CfPluginRepo mirrors models.PluginRepo.
+type CfPluginRepo struct {
+	Name string
+	URL  string
+}
+
+// CfConfigPath is where the CLI keeps its config for cfHome.
+func CfConfigPath(cfHome string) string {
+	return filepath.Join(cfHome, ".cf", "config.json")
+}
+
+// PluginConfigPath is where the CLI keeps its plugin config for pluginHome.
+func PluginConfigPath(pluginHome string) string {
+	return filepath.Join(pluginHome, ".cf", "plugins", "config.json")
+}
+
+// ReadCfConfig reads the config the CLI persisted in cfHome. Like
+// coreconfig.Data.JSONUnmarshalV3, a config of any version other than 3
+// reads as empty.
+func ReadCfConfig(cfHome string) (CfConfigData, error) {
+	var data CfConfigData
+
+	contents, err := ioutil.ReadFile(CfConfigPath(cfHome))
+	if err != nil {
+		return data, err
+	}
+
+	err = json.Unmarshal(contents, &data)
+	if err != nil {
+		return data, fmt.Errorf("parsing %s: %s", CfConfigPath(cfHome), err)
+	}
+
+	if data.ConfigVersion != 3 {
+		return CfConfigData{}, nil
+	}
+
+	return data, nil
+}
+
+// ReadPluginConfig reads the plugin config the CLI persisted in pluginHome.
+func ReadPluginConfig(pluginHome string) (pluginconfig.PluginData, error) {
+	var data pluginconfig.PluginData
+
+	contents, err := ioutil.ReadFile(PluginConfigPath(pluginHome))
+	if err != nil {
+		return data, err
+	}
+
+	err = json.Unmarshal(contents, &data)
+	if err != nil {
+		return data, fmt.Errorf("parsing %s: %s", PluginConfigPath(pluginHome), err)
+	}
+
+	return data, nil
+}
+
+// WriteCfConfig seeds cfHome with data as the CLI would persist it: version 3,
+// indented, with the same file and directory permissions.
+func WriteCfConfig(cfHome string, data CfConfigData) error {
+	data.ConfigVersion = 3
+	contents, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeCfHomeFile(CfConfigPath(cfHome), contents)
+}
+
+// WritePluginConfig seeds pluginHome with data as the CLI would persist it.
+func WritePluginConfig(pluginHome string, data pluginconfig.PluginData) error {
+	contents, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeCfHomeFile(PluginConfigPath(pluginHome), contents)
+}
+
+func writeCfHomeFile(path string, contents []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, contents, 0600)
+}
+
+// UseTempCfHome points CF_HOME and CF_PLUGIN_HOME at a new, empty directory
+// named after prefix, so the CLI starts without a target, a login or plugins.
+// Call the returned func from AfterEach: it restores both variables and
+// removes the directory, even when a spec left read-only files in it.
+func UseTempCfHome(prefix string) (string, func()) {
+	cfHome, err := ioutil.TempDir("", prefix)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	originalCfHome := os.Getenv("CF_HOME")
+	originalCfPluginHome := os.Getenv("CF_PLUGIN_HOME")
+	os.Setenv("CF_HOME", cfHome)
+	os.Setenv("CF_PLUGIN_HOME", cfHome)
+
+	return cfHome, func() {
+		os.Setenv("CF_HOME", originalCfHome)
+		os.Setenv("CF_PLUGIN_HOME", originalCfPluginHome)
+
+		filepath.Walk(cfHome, func(path string, info os.FileInfo, err error) error {
+			os.Chmod(path, 0700)
+			return nil
+		})
+		os.RemoveAll(cfHome)
+	}
+}
+
+// CurrentCfConfig reads the config of the CF_HOME the current user context
+// runs in, e.g. inside AsUser or between InitiateUserContext and
+// RestoreUserContext.
+func CurrentCfConfig() CfConfigData {
+	cfHome := os.Getenv("CF_HOME")
+	Expect(cfHome).NotTo(BeEmpty(), "CF_HOME is not set; read the config inside a user context")
+
+	data, err := ReadCfConfig(cfHome)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+// CurrentPluginConfig reads the plugin config from where the CLI looks for
+// it: $CF_PLUGIN_HOME, falling back to the user's home directory.
+func CurrentPluginConfig() pluginconfig.PluginData {
+	data, err := ReadPluginConfig(pluginHome())
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+// pluginHome matches confighelpers.PluginRepoDir.
+func pluginHome() string {
+	if os.Getenv("CF_PLUGIN_HOME") != "" {
+		return os.Getenv("CF_PLUGIN_HOME")
+	}
+
+	if runtime.GOOS == "windows" {
+		home := os.Getenv("HOMEDRIVE") + os.Getenv("HOMEPATH")
+		if home == "" {
+			home = os.Getenv("USERPROFILE")
+		}
+		return home
+	}
+
+	return os.Getenv("HOME")
+}
@@ -0,0 +1,133 @@
+package helpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CF_HOME snapshots", func() {
+	var cfHome string
+
+	writeFile := func(path, contents string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		cfHome, err = ioutil.TempDir("", "gats-cf-home")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cfHome)
+	})
+
+	It("reads what the CLI persisted in config.json", func() {
+		writeFile(CfConfigPath(cfHome), `{
+  "ConfigVersion": 3,
+  "Target": "https://api.bosh-lite.com",
+  "APIVersion": "2.54.0",
+  "OrganizationFields": {"GUID": "org-guid", "Name": "CATS-ORG-1", "QuotaDefinition": {"name": "default", "memory_limit": 10240}},
+  "SpaceFields": {"GUID": "space-guid", "Name": "CATS-SPACE-1", "AllowSSH": true},
+  "SSLDisabled": true,
+  "AsyncTimeout": 5,
+  "Locale": "fr-FR",
+  "PluginRepos": [{"Name": "CF-Community", "URL": "https://plugins.cloudfoundry.org"}]
+}`)
+
+		data, err := ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Target).To(Equal("https://api.bosh-lite.com"))
+		Expect(data.APIVersion).To(Equal("2.54.0"))
+		Expect(data.OrganizationFields.Name).To(Equal("CATS-ORG-1"))
+		Expect(data.OrganizationFields.QuotaDefinition.MemoryLimit).To(Equal(int64(10240)))
+		Expect(data.SpaceFields).To(Equal(CfSpaceFields{GUID: "space-guid", Name: "CATS-SPACE-1", AllowSSH: true}))
+		Expect(data.SSLDisabled).To(BeTrue())
+		Expect(data.AsyncTimeout).To(Equal(uint(5)))
+		Expect(data.Locale).To(Equal("fr-FR"))
+		Expect(data.PluginRepos).To(Equal([]CfPluginRepo{{Name: "CF-Community", URL: "https://plugins.cloudfoundry.org"}}))
+	})
+
+	It("reads a config of another version as empty, like the CLI", func() {
+		writeFile(CfConfigPath(cfHome), `{"ConfigVersion": 2, "Target": "https://api.bosh-lite.com"}`)
+
+		data, err := ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(CfConfigData{}))
+	})
+
+	It("reads the installed plugins", func() {
+		writeFile(PluginConfigPath(cfHome), `{"Plugins": {"GatsPlugin": {
+  "Location": "/home/gats/.cf/plugins/plugin_linux_amd64",
+  "Version": {"Major": 1, "Minor": 2, "Build": 3},
+  "Commands": [{"Name": "LoggregatorEndpoint", "HelpText": "gets the loggregator endpoint"}]
+}}}`)
+
+		data, err := ReadPluginConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Plugins).To(HaveKey("GatsPlugin"))
+		Expect(data.Plugins["GatsPlugin"].Version.Minor).To(Equal(2))
+		Expect(data.Plugins["GatsPlugin"].Commands[0].Name).To(Equal("LoggregatorEndpoint"))
+	})
+
+	It("reports a config that isn't JSON", func() {
+		writeFile(CfConfigPath(cfHome), `{`)
+
+		_, err := ReadCfConfig(cfHome)
+		Expect(err).To(MatchError(ContainSubstring("parsing")))
+	})
+})
+
+var _ = Describe("Seeding CF_HOME", func() {
+	var cfHome string
+
+	BeforeEach(func() {
+		var err error
+		cfHome, err = ioutil.TempDir("", "gats-cf-home")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cfHome)
+	})
+
+	It("writes a version 3 config that reads back the same", func() {
+		Expect(WriteCfConfig(cfHome, CfConfigData{Target: "https://api.bosh-lite.com", AsyncTimeout: 3})).To(Succeed())
+
+		data, err := ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.ConfigVersion).To(Equal(3))
+		Expect(data.Target).To(Equal("https://api.bosh-lite.com"))
+		Expect(data.AsyncTimeout).To(Equal(uint(3)))
+	})
+})
+
+var _ = Describe("A temporary CF_HOME", func() {
+	It("points CF_HOME and CF_PLUGIN_HOME at an empty directory until restored", func() {
+		os.Setenv("CF_HOME", "/gats/original")
+		defer os.Unsetenv("CF_HOME")
+
+		cfHome, restoreCfHome := UseTempCfHome("gats-cf-home")
+		Expect(os.Getenv("CF_HOME")).To(Equal(cfHome))
+		Expect(os.Getenv("CF_PLUGIN_HOME")).To(Equal(cfHome))
+		Expect(ioutil.ReadDir(cfHome)).To(BeEmpty())
+
+		// The CLI writes some files read-only.
+		readOnly := filepath.Join(cfHome, ".cf", "plugins")
+		Expect(os.MkdirAll(readOnly, 0700)).To(Succeed())
+		Expect(os.Chmod(filepath.Dir(readOnly), 0500)).To(Succeed())
+
+		restoreCfHome()
+		Expect(os.Getenv("CF_HOME")).To(Equal("/gats/original"))
+		Expect(os.Getenv("CF_PLUGIN_HOME")).To(BeEmpty())
+		_, err := os.Stat(cfHome)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+
+	"github.com/blang/semver"
+	. "github.com/onsi/ginkgo"
+)
+
+const BuiltFromSource = "BUILT_FROM_SOURCE"
+
+var cliVersionPattern = regexp.MustCompile(`version (\d+\.\d+\.\d+|` + BuiltFromSource + `)`)
+
+type CliVersion struct {
+	Version         semver.Version
+	BuiltFromSource bool
+}
+
+// DetectCliVersion runs `cf version` with binary, which is looked up on PATH
+// when it has no path separator.
+func DetectCliVersion(binary string) (CliVersion, error) {
+	output, err := exec.Command(binary, "version").CombinedOutput()
+	if err != nil {
+		return CliVersion{}, fmt.Errorf("running `cf version` failed: %s\n%s", err, output)
+	}
+
+	return ParseCliVersion(string(output))
+}
+
+// ParseCliVersion reads the output of `cf version`.
+func ParseCliVersion(output string) (CliVersion, error) {
+	matches := cliVersionPattern.FindStringSubmatch(output)
+	if matches == nil {
+		return CliVersion{}, fmt.Errorf("could not find a version in `cf version` output %q", output)
+	}
+
+	if matches[1] == BuiltFromSource {
+		return CliVersion{BuiltFromSource: true}, nil
+	}
+
+	version, err := semver.Make(matches[1])
+	if err != nil {
+		return CliVersion{}, err
+	}
+
+	return CliVersion{Version: version}, nil
+}
+
+// AtLeast reports whether the CLI is minimum or newer. Like the CLI's own
+// IsMinCliVersion, a binary built from source counts as the newest release.
+func (v CliVersion) AtLeast(minimum string) (bool, error) {
+	if v.BuiltFromSource {
+		return true, nil
+	}
+
+	required, err := semver.Make(minimum)
+	if err != nil {
+		return false, err
+	}
+
+	return v.Version.GTE(required), nil
+}
+
+// AtMost reports whether the CLI is maximum or older. A binary built from
+// source is newer than every maximum.
+func (v CliVersion) AtMost(maximum string) (bool, error) {
+	if v.BuiltFromSource {
+		return false, nil
+	}
+
+	limit, err := semver.Make(maximum)
+	if err != nil {
+		return false, err
+	}
+
+	return v.Version.LTE(limit), nil
+}
+
+// CliVersionRange bounds the CLI versions a spec applies to. Either end may
+// be left empty.
+type CliVersionRange struct {
+	Minimum string
+	Maximum string
+}
+
+// Allows reports whether version is within the range, and if not, why.
+func (r CliVersionRange) Allows(version CliVersion) (bool, string, error) {
+	if r.Minimum != "" {
+		ok, err := version.AtLeast(r.Minimum)
+		if err != nil || !ok {
+			return false, fmt.Sprintf("requires cf >= %s, running %s", r.Minimum, version), err
+		}
+	}
+
+	if r.Maximum != "" {
+		ok, err := version.AtMost(r.Maximum)
+		if err != nil || !ok {
+			return false, fmt.Sprintf("requires cf <= %s, running %s", r.Maximum, version), err
+		}
+	}
+
+	return true, "", nil
+}
+
+var (
+	currentCliVersion    CliVersion
+	currentCliVersionErr error
+	detectCliVersionOnce sync.Once
+)
+
+// CurrentCliVersion detects the version of the cf the suites run once per
+// suite process. In an A/B run that is the binary GATS_AB_SIDE selects.
+func CurrentCliVersion() (CliVersion, error) {
+	detectCliVersionOnce.Do(func() {
+		binary := "cf"
+		if os.Getenv(ABSideEnvVar) != "" {
+			binary = LoadConfig().CfBinary()
+		}
+		currentCliVersion, currentCliVersionErr = DetectCliVersion(binary)
+	})
+	return currentCliVersion, currentCliVersionErr
+}
+
+// SkipUnlessCliVersion skips the current spec when the cf the suites run is
+// outside r, or when its version can't be told.
+func SkipUnlessCliVersion(r CliVersionRange) {
+	version, err := CurrentCliVersion()
+	if err != nil {
+		Skip(err.Error())
+	}
+
+	ok, reason, err := r.Allows(version)
+	if err != nil {
+		Fail(err.Error())
+	}
+	if !ok {
+		Skip(reason)
+	}
+}
+
+// CliIt declares a spec that only applies to the CLI versions in r. The
+// version is checked when the spec runs, so a cf that can't report it only
+// affects these specs, and against any other version the spec is skipped.
+func CliIt(r CliVersionRange, text string, body func(), timeout ...float64) bool {
+	return It(text, func() {
+		SkipUnlessCliVersion(r)
+		body()
+	}, timeout...)
+}
+
+func (v CliVersion) String() string {
+	if v.BuiltFromSource {
+		return BuiltFromSource
+	}
+	return v.Version.String()
+}
@@ -0,0 +1,136 @@
+package helpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CliVersion", func() {
+	It("parses released versions", func() {
+		version, err := ParseCliVersion("cf version 6.22.2+a95e24c-2016-10-27\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version.String()).To(Equal("6.22.2"))
+
+		Expect(version.AtLeast("6.21.0")).To(BeTrue())
+		Expect(version.AtLeast("6.23.0")).To(BeFalse())
+	})
+
+	It("treats binaries built from source as the newest version", func() {
+		version, err := ParseCliVersion("cf version BUILT_FROM_SOURCE-BUILT_AT_UNKNOWN_TIME\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version.BuiltFromSource).To(BeTrue())
+		Expect(version.AtLeast("99.0.0")).To(BeTrue())
+	})
+
+	It("errors on output without a version", func() {
+		_, err := ParseCliVersion("command not found")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CliVersionRange", func() {
+	var version CliVersion
+
+	BeforeEach(func() {
+		var err error
+		version, err = ParseCliVersion("cf version 6.21.1+6fd3c9f-2016-08-10\n")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("allows versions within both ends", func() {
+		ok, _, err := CliVersionRange{Minimum: "6.21.0", Maximum: "6.22.0"}.Allows(version)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("explains why a version is too old", func() {
+		ok, reason, err := CliVersionRange{Minimum: "6.22.0"}.Allows(version)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(reason).To(Equal("requires cf >= 6.22.0, running 6.21.1"))
+	})
+
+	It("explains why a version is too new", func() {
+		ok, reason, err := CliVersionRange{Maximum: "6.20.0"}.Allows(version)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(reason).To(Equal("requires cf <= 6.20.0, running 6.21.1"))
+	})
+
+	It("puts binaries built from source above every maximum", func() {
+		fromSource, err := ParseCliVersion("cf version BUILT_FROM_SOURCE-BUILT_AT_UNKNOWN_TIME\n")
+		Expect(err).NotTo(HaveOccurred())
+
+		ok, _, err := CliVersionRange{Minimum: "6.21.0"}.Allows(fromSource)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		ok, _, err = CliVersionRange{Maximum: "6.21.0"}.Allows(fromSource)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rejects a malformed bound", func() {
+		_, _, err := CliVersionRange{Minimum: "six"}.Allows(version)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CliIt", func() {
+	var (
+		binDir       string
+		originalPath string
+		ran          bool
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("fakes cf with a shell script")
+		}
+		ran = false
+
+		var err error
+		binDir, err = ioutil.TempDir("", "gats-cli-version")
+		Expect(err).NotTo(HaveOccurred())
+		script := "#!/bin/sh\necho 'cf version 6.21.1+6fd3c9f-2016-08-10'\n"
+		Expect(ioutil.WriteFile(filepath.Join(binDir, "cf"), []byte(script), 0755)).To(Succeed())
+
+		originalPath = os.Getenv("PATH")
+		os.Setenv("PATH", binDir+string(os.PathListSeparator)+originalPath)
+	})
+
+	AfterEach(func() {
+		if runtime.GOOS == "windows" {
+			return
+		}
+		os.Setenv("PATH", originalPath)
+		os.RemoveAll(binDir)
+	})
+
+	Context("when cf is within the range", func() {
+		AfterEach(func() {
+			Expect(ran).To(BeTrue())
+		})
+
+		CliIt(CliVersionRange{Minimum: "6.21.0"}, "runs the spec", func() {
+			ran = true
+		})
+	})
+
+	Context("when cf is outside the range", func() {
+		AfterEach(func() {
+			Expect(ran).To(BeFalse())
+		})
+
+		CliIt(CliVersionRange{Minimum: "6.22.0"}, "skips the spec", func() {
+			ran = true
+		})
+	})
+})
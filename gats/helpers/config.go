@@ -0,0 +1,368 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	acceptanceTestHelpers "github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+)
+
+const EnvironmentOverridePrefix = "GATS_"
+
+// ABSideEnvVar selects which of cf_binary_a and cf_binary_b an A/B run uses.
+// Package ab owns A/B runs but imports helpers, so the name lives here.
+const ABSideEnvVar = "GATS_AB_SIDE"
+
+// Config extends the cf-test-helpers config with the keys that
+// bin/create_gats_config writes for gats.
+type Config struct {
+	acceptanceTestHelpers.Config
+
+	CfUser         string `json:"cf_user"`
+	CfUserPassword string `json:"cf_user_password"`
+	CfOrg          string `json:"cf_org"`
+	CfSpace        string `json:"cf_space"`
+
+	AdminClient       string `json:"admin_client"`
+	AdminClientSecret string `json:"admin_client_secret"`
+
+	// PoolSize is the number of org/space/user bundles created per parallel node.
+	PoolSize int `json:"pool_size"`
+
+	// CfBinaryA and CfBinaryB are the two cf binaries compared by an A/B run.
+	CfBinaryA string `json:"cf_binary_a"`
+	CfBinaryB string `json:"cf_binary_b"`
+
+	secretSources map[string]string
+}
+
+// secretConfigKeys are masked whenever the config is printed. Their values
+// may also be given as `env:VAR` or `file:/path` references.
+var secretConfigKeys = map[string]bool{
+	"admin_password":         true,
+	"admin_client_secret":    true,
+	"existing_user_password": true,
+	"test_password":          true,
+	"cf_user_password":       true,
+	"docker_password":        true,
+}
+
+// defaultConfig matches the defaults cf-test-helpers applies in Load.
+var defaultConfig = Config{
+	Config: acceptanceTestHelpers.Config{
+		PersistentAppHost:      "CATS-persistent-app",
+		PersistentAppSpace:     "CATS-persistent-space",
+		PersistentAppOrg:       "CATS-persistent-org",
+		PersistentAppQuotaName: "CATS-persistent-quota",
+
+		StaticFileBuildpackName: "staticfile_buildpack",
+		JavaBuildpackName:       "java_buildpack",
+		RubyBuildpackName:       "ruby_buildpack",
+		NodejsBuildpackName:     "nodejs_buildpack",
+		GoBuildpackName:         "go_buildpack",
+		PythonBuildpackName:     "python_buildpack",
+		PhpBuildpackName:        "php_buildpack",
+		BinaryBuildpackName:     "binary_buildpack",
+
+		ArtifactsDirectory: filepath.Join("..", "results"),
+	},
+}
+
+// ConfigErrors collects every problem found while loading a config.
+type ConfigErrors []error
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "invalid gats config:\n  " + strings.Join(messages, "\n  ")
+}
+
+var loadedConfig *Config
+
+// LoadConfig loads the config at $CONFIG once and panics if it is invalid.
+func LoadConfig() Config {
+	if loadedConfig != nil {
+		return *loadedConfig
+	}
+
+	config, warnings, err := Load(acceptanceTestHelpers.ConfigPath())
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "gats config warning:", warning)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	config.registerSecrets()
+	loadedConfig = &config
+	return config
+}
+
+// Load reads the config at path, applies GATS_* environment overrides,
+// resolves secret references and validates the result. Unknown JSON keys are
+// returned as warnings; all validation problems are returned together as
+// ConfigErrors.
+func Load(path string) (Config, []string, error) {
+	config := defaultConfig
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, nil, err
+	}
+
+	err = json.Unmarshal(contents, &config)
+	if err != nil {
+		return config, nil, fmt.Errorf("parsing %s: %s", path, err)
+	}
+
+	warnings, err := unknownKeyWarnings(contents)
+	if err != nil {
+		return config, nil, fmt.Errorf("parsing %s: %s", path, err)
+	}
+
+	errs := applyEnvironmentOverrides(&config)
+	errs = append(errs, resolveSecrets(&config)...)
+	errs = append(errs, config.validate()...)
+	if len(errs) > 0 {
+		return config, warnings, errs
+	}
+
+	useAdminClientCredentials(&config)
+
+	if config.TimeoutScale <= 0 {
+		config.TimeoutScale = 1.0
+	}
+
+	runner.SkipSSLValidation = config.SkipSSLValidation
+	return config, warnings, nil
+}
+
+func (c Config) validate() ConfigErrors {
+	var errs ConfigErrors
+
+	required := []struct{ key, value string }{
+		{"api", c.ApiEndpoint},
+	}
+	if c.AdminClient != "" {
+		required = append(required, struct{ key, value string }{"admin_client_secret", c.AdminClientSecret})
+	} else {
+		required = append(required,
+			struct{ key, value string }{"admin_user", c.AdminUser},
+			struct{ key, value string }{"admin_password", c.AdminPassword},
+		)
+	}
+	if c.UseExistingUser {
+		required = append(required,
+			struct{ key, value string }{"existing_user", c.ExistingUser},
+			struct{ key, value string }{"existing_user_password", c.ExistingUserPassword},
+		)
+	}
+	if c.CfUser != "" {
+		required = append(required, struct{ key, value string }{"cf_user_password", c.CfUserPassword})
+	}
+	if c.CfSpace != "" {
+		required = append(required, struct{ key, value string }{"cf_org", c.CfOrg})
+	}
+
+	for _, r := range required {
+		if r.value == "" {
+			errs = append(errs, fmt.Errorf("missing configuration '%s'", r.key))
+		}
+	}
+
+	if (c.CfBinaryA == "") != (c.CfBinaryB == "") {
+		errs = append(errs, errors.New("'cf_binary_a' and 'cf_binary_b' must be set together"))
+	}
+
+	if c.PoolSize < 0 {
+		errs = append(errs, fmt.Errorf("'pool_size' must not be negative, got %d", c.PoolSize))
+	}
+
+	if c.TimeoutScale < 0 {
+		errs = append(errs, fmt.Errorf("'timeout_scale' must not be negative, got %v", c.TimeoutScale))
+	}
+
+	return errs
+}
+
+// Masked renders the effective config as JSON with every secret replaced.
+func (c Config) Masked() string {
+	values := map[string]interface{}{}
+	eachConfigField(&c, func(key string, field reflect.Value) {
+		value := field.Interface()
+		if secretConfigKeys[key] && field.String() != "" {
+			value = RedactedPlaceholder
+			if source, ok := c.secretSources[key]; ok {
+				value = fmt.Sprintf("%s (from %s)", RedactedPlaceholder, source)
+			}
+		}
+		values[key] = value
+	})
+
+	contents, _ := json.MarshalIndent(values, "", "  ")
+	return string(contents)
+}
+
+// CfBinary is the cf the suites run: cf_binary_a or cf_binary_b when
+// GATS_AB_SIDE selects one, otherwise the cf on PATH.
+func (c Config) CfBinary() string {
+	switch os.Getenv(ABSideEnvVar) {
+	case "a":
+		return c.CfBinaryA
+	case "b":
+		return c.CfBinaryB
+	}
+	return "cf"
+}
+
+// registerSecrets hands every configured secret to the redaction layer.
+func (c Config) registerSecrets() {
+	eachConfigField(&c, func(key string, field reflect.Value) {
+		if secretConfigKeys[key] {
+			RegisterSecret(field.String())
+		}
+	})
+}
+
+// ConfigKeys lists every JSON key a gats config understands.
+func ConfigKeys() []string {
+	var keys []string
+	eachConfigField(&Config{}, func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvironmentOverrideName is the variable that overrides key, e.g. GATS_ADMIN_PASSWORD.
+func EnvironmentOverrideName(key string) string {
+	return EnvironmentOverridePrefix + strings.ToUpper(key)
+}
+
+func unknownKeyWarnings(contents []byte) ([]string, error) {
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(contents, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	for _, key := range ConfigKeys() {
+		known[key] = true
+	}
+
+	var warnings []string
+	for key := range raw {
+		if !known[key] {
+			warnings = append(warnings, fmt.Sprintf("unknown key '%s' is ignored", key))
+		}
+	}
+	sort.Strings(warnings)
+
+	return warnings, nil
+}
+
+func applyEnvironmentOverrides(config *Config) ConfigErrors {
+	var errs ConfigErrors
+
+	eachConfigField(config, func(key string, field reflect.Value) {
+		name := EnvironmentOverrideName(key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		err := setField(field, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", name, err))
+		}
+	})
+
+	return errs
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			nanoseconds, intErr := strconv.ParseInt(value, 10, 64)
+			if intErr != nil {
+				return fmt.Errorf("'%s' is not a duration", value)
+			}
+			duration = time.Duration(nanoseconds)
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a boolean", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+		field.SetInt(i)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("cannot override a %s", field.Kind())
+	}
+
+	return nil
+}
+
+// eachConfigField visits every JSON-tagged field of config, including the
+// fields of the embedded cf-test-helpers config.
+func eachConfigField(config *Config, visit func(key string, field reflect.Value)) {
+	var walk func(value reflect.Value)
+	walk = func(value reflect.Value) {
+		for i := 0; i < value.NumField(); i++ {
+			fieldType := value.Type().Field(i)
+			if fieldType.Anonymous {
+				walk(value.Field(i))
+				continue
+			}
+
+			key := strings.Split(fieldType.Tag.Get("json"), ",")[0]
+			if key == "" || key == "-" {
+				continue
+			}
+
+			visit(key, value.Field(i))
+		}
+	}
+
+	walk(reflect.ValueOf(config).Elem())
+}
@@ -0,0 +1,170 @@
+package helpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	var (
+		directory string
+		path      string
+	)
+
+	writeConfig := func(contents string) {
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "gats-config")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(directory, "gats_config.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	It("loads the gats keys alongside the cf-test-helpers keys", func() {
+		writeConfig(`{
+			"api": "api.bosh-lite.com",
+			"admin_user": "admin",
+			"admin_password": "admin",
+			"cf_user": "user",
+			"cf_user_password": "pass",
+			"cf_org": "org",
+			"cf_space": "space"
+		}`)
+
+		config, warnings, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+		Expect(config.ApiEndpoint).To(Equal("api.bosh-lite.com"))
+		Expect(config.CfUser).To(Equal("user"))
+		Expect(config.CfOrg).To(Equal("org"))
+		Expect(config.CfSpace).To(Equal("space"))
+		Expect(config.PersistentAppOrg).To(Equal("CATS-persistent-org"))
+		Expect(config.TimeoutScale).To(Equal(1.0))
+	})
+
+	It("reports every validation error at once", func() {
+		writeConfig(`{"cf_user": "user", "cf_space": "space"}`)
+
+		_, _, err := Load(path)
+		Expect(err).To(BeAssignableToTypeOf(ConfigErrors{}))
+		Expect(err.(ConfigErrors)).To(HaveLen(5))
+		Expect(err.Error()).To(ContainSubstring("missing configuration 'api'"))
+		Expect(err.Error()).To(ContainSubstring("missing configuration 'admin_password'"))
+		Expect(err.Error()).To(ContainSubstring("missing configuration 'cf_org'"))
+	})
+
+	It("warns about unknown keys", func() {
+		writeConfig(`{"api": "a", "admin_user": "u", "admin_password": "p", "admin_pasword": "typo"}`)
+
+		_, warnings, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf("unknown key 'admin_pasword' is ignored"))
+	})
+
+	Describe("environment overrides", func() {
+		BeforeEach(func() {
+			os.Setenv("GATS_API", "api.example.com")
+			os.Setenv("GATS_SKIP_SSL_VALIDATION", "true")
+			os.Setenv("GATS_DEFAULT_TIMEOUT", "45s")
+			os.Setenv("GATS_DOCKER_PARAMETERS", "-a, -b")
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("GATS_API")
+			os.Unsetenv("GATS_SKIP_SSL_VALIDATION")
+			os.Unsetenv("GATS_DEFAULT_TIMEOUT")
+			os.Unsetenv("GATS_DOCKER_PARAMETERS")
+			os.Unsetenv("GATS_TIMEOUT_SCALE")
+		})
+
+		It("overrides keys from GATS_* variables", func() {
+			writeConfig(`{"api": "api.bosh-lite.com", "admin_user": "u", "admin_password": "p"}`)
+
+			config, _, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.ApiEndpoint).To(Equal("api.example.com"))
+			Expect(config.SkipSSLValidation).To(BeTrue())
+			Expect(config.DefaultTimeout).To(Equal(45 * time.Second))
+			Expect(config.DockerParameters).To(Equal([]string{"-a", "-b"}))
+		})
+
+		It("reports unparseable overrides", func() {
+			os.Setenv("GATS_TIMEOUT_SCALE", "fast")
+			writeConfig(`{"api": "a", "admin_user": "u", "admin_password": "p"}`)
+
+			_, _, err := Load(path)
+			Expect(err).To(MatchError(ContainSubstring("GATS_TIMEOUT_SCALE: 'fast' is not a number")))
+		})
+	})
+
+	It("masks secrets when printed", func() {
+		writeConfig(`{"api": "a", "admin_user": "admin", "admin_password": "s3cret", "cf_user_password": "also-s3cret"}`)
+
+		config, _, err := Load(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		masked := config.Masked()
+		Expect(masked).To(ContainSubstring(`"admin_user": "admin"`))
+		Expect(masked).To(ContainSubstring(`"admin_password": "[REDACTED]"`))
+		Expect(masked).NotTo(ContainSubstring("s3cret"))
+	})
+
+	Describe("secret references", func() {
+		BeforeEach(func() {
+			os.Setenv("GATS_TEST_ADMIN_PASSWORD", "from-env")
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("GATS_TEST_ADMIN_PASSWORD")
+		})
+
+		It("resolves env: and file: references", func() {
+			secretFile := filepath.Join(directory, "cf-user-password")
+			Expect(ioutil.WriteFile(secretFile, []byte("from-file\n"), 0600)).To(Succeed())
+			writeConfig(`{
+				"api": "a",
+				"admin_user": "admin",
+				"admin_password": "env:GATS_TEST_ADMIN_PASSWORD",
+				"cf_user": "user",
+				"cf_user_password": "file:` + secretFile + `"
+			}`)
+
+			config, _, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.AdminPassword).To(Equal("from-env"))
+			Expect(config.CfUserPassword).To(Equal("from-file"))
+			Expect(config.Masked()).To(ContainSubstring(`"admin_password": "[REDACTED] (from env:GATS_TEST_ADMIN_PASSWORD)"`))
+			Expect(config.Masked()).NotTo(ContainSubstring("from-env"))
+		})
+
+		It("reports unresolvable references without their values", func() {
+			writeConfig(`{"api": "a", "admin_user": "admin", "admin_password": "env:GATS_TEST_MISSING", "test_password": "file:/does/not/exist"}`)
+
+			_, _, err := Load(path)
+			Expect(err).To(MatchError(ContainSubstring("'admin_password' refers to environment variable GATS_TEST_MISSING, which is not set")))
+			Expect(err).To(MatchError(ContainSubstring("'test_password' refers to file /does/not/exist, which cannot be read")))
+		})
+
+		It("uses a UAA client as the admin identity", func() {
+			writeConfig(`{"api": "a", "admin_client": "gats-admin", "admin_client_secret": "env:GATS_TEST_ADMIN_PASSWORD"}`)
+
+			config, _, err := Load(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.AdminUser).To(Equal("gats-admin"))
+			Expect(config.AdminPassword).To(Equal("from-env"))
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/gomega"
+)
+
+// ExpectLinesInOrder checks that output contains lines in order, each one
+// after the end of the one before.
+func ExpectLinesInOrder(output string, lines []string) {
+	remaining := output
+	for _, line := range lines {
+		index := strings.Index(remaining, line)
+		if !ExpectWithOffset(1, index).To(BeNumerically(">=", 0), "expected %q after the earlier lines in:\n%s", line, output) {
+			return
+		}
+		remaining = remaining[index+len(line):]
+	}
+}
+
+type lineEdit struct {
+	// op is ' ' for a line in both texts, '-' for a line only in the first
+	// and '+' for a line only in the second.
+	op   byte
+	line string
+}
+
+// LineDiff returns a minimal line diff of a and b, with lines only in a
+// prefixed "- " and lines only in b prefixed "+ ". It is empty when they match.
+func LineDiff(a, b string) []string {
+	if a == b {
+		return nil
+	}
+
+	var diff []string
+	for _, edit := range editScript(splitLines(a), splitLines(b)) {
+		if edit.op != ' ' {
+			diff = append(diff, string(edit.op)+" "+edit.line)
+		}
+	}
+	return diff
+}
+
+// UnifiedDiff renders the differences between a and b like `diff -u`: hunks
+// headed "@@ -start,count +start,count @@" with up to context unchanged lines
+// around each change. It is empty when they match.
+func UnifiedDiff(a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+
+	edits := editScript(splitLines(a), splitLines(b))
+
+	// Collect the ranges of edits to print, merging changes whose context
+	// overlaps.
+	type hunk struct{ start, end int }
+	var hunks []hunk
+	for i, edit := range edits {
+		if edit.op == ' ' {
+			continue
+		}
+
+		start, end := i-context, i+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+		} else {
+			hunks = append(hunks, hunk{start, end})
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	lineA, lineB, next := 1, 1, 0
+	for _, h := range hunks {
+		for ; next < h.start; next++ {
+			lineA++
+			lineB++
+		}
+
+		countA, countB := 0, 0
+		for _, edit := range edits[h.start:h.end] {
+			if edit.op != '+' {
+				countA++
+			}
+			if edit.op != '-' {
+				countB++
+			}
+		}
+
+		fmt.Fprintf(buffer, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+		for _, edit := range edits[h.start:h.end] {
+			fmt.Fprintf(buffer, "%c%s\n", edit.op, edit.line)
+		}
+
+		lineA += countA
+		lineB += countB
+		next = h.end
+	}
+
+	return buffer.String()
+}
+
+// editScript turns linesA into linesB through their longest common
+// subsequence.
+func editScript(linesA, linesB []string) []lineEdit {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// linesA[i:] and linesB[j:].
+	lcs := make([][]int, len(linesA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(linesB)+1)
+	}
+	for i := len(linesA) - 1; i >= 0; i-- {
+		for j := len(linesB) - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []lineEdit
+	i, j := 0, 0
+	for i < len(linesA) && j < len(linesB) {
+		switch {
+		case linesA[i] == linesB[j]:
+			edits = append(edits, lineEdit{' ', linesA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, lineEdit{'-', linesA[i]})
+			i++
+		default:
+			edits = append(edits, lineEdit{'+', linesB[j]})
+			j++
+		}
+	}
+	for ; i < len(linesA); i++ {
+		edits = append(edits, lineEdit{'-', linesA[i]})
+	}
+	for ; j < len(linesB); j++ {
+		edits = append(edits, lineEdit{'+', linesB[j]})
+	}
+
+	return edits
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
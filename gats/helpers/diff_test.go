@@ -0,0 +1,50 @@
+package helpers_test
+
+import (
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("diffs", func() {
+	const (
+		before = "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+		after  = "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	)
+
+	It("lists changed lines", func() {
+		Expect(LineDiff(before, after)).To(Equal([]string{"- two", "+ 2", "+ ten"}))
+		Expect(LineDiff(before, before)).To(BeEmpty())
+	})
+
+	It("renders hunks with context", func() {
+		Expect(UnifiedDiff(before, after, 2)).To(Equal(
+			"@@ -1,4 +1,4 @@\n" +
+				" one\n" +
+				"-two\n" +
+				"+2\n" +
+				" three\n" +
+				" four\n" +
+				"@@ -8,2 +8,3 @@\n" +
+				" eight\n" +
+				" nine\n" +
+				"+ten\n",
+		))
+	})
+
+	It("merges hunks whose context overlaps", func() {
+		Expect(UnifiedDiff(before, after, 4)).To(HavePrefix("@@ -1,9 +1,10 @@\n"))
+		Expect(UnifiedDiff(before, before, 3)).To(BeEmpty())
+	})
+
+	It("finds lines in order", func() {
+		ExpectLinesInOrder("FAILED\nServer error, status code: 503\n", []string{"FAILED", "status code: 503"})
+
+		failures := InterceptGomegaFailures(func() {
+			ExpectLinesInOrder("status code: 503\nFAILED\n", []string{"FAILED", "status code: 503"})
+		})
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0]).To(ContainSubstring(`expected "status code: 503" after the earlier lines`))
+	})
+})
@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	NormalizedTimestamp  = "2000-01-01T00:00:00Z"
+	NormalizedTimeTag    = "2000_01_01-00h00m00s"
+	NormalizedCliVersion = "{{cf-version}}"
+)
+
+var (
+	outputGuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+	// outputTimestampPatterns cover RFC 3339 (API bodies, `cf events`, `cf
+	// logs`) and the time.UnixDate style `cf app` prints for "since".
+	outputTimestampPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|\s?[+-]\d{2}:?\d{2}|\s[A-Z]{3,4})?`),
+		regexp.MustCompile(`(Mon|Tue|Wed|Thu|Fri|Sat|Sun) (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [ \d]\d \d{2}:\d{2}:\d{2}( [A-Z]{3,4})? \d{4}`),
+	}
+
+	outputTimeTagPattern = regexp.MustCompile(`\d{4}_\d{2}_\d{2}-\d{2}h\d{2}m\d{2}(\.\d+)?s`)
+
+	// cliVersionBannerPattern matches the version printed by `cf version` and
+	// in the VERSION section of `cf help`.
+	cliVersionBannerPattern = regexp.MustCompile(`(cf version |VERSION:\s+)(\d+\.\d+\.\d+(\+[0-9a-f]+)?(-\d{4}-\d{2}-\d{2})?|` + BuiltFromSource + `(-BUILT_AT_UNKNOWN_TIME)?)`)
+)
+
+// OutputNormalizer rewrites cf output so that two runs of the same commands
+// compare equal unless the CLI behaved differently: GUIDs become sequential
+// placeholders in order of first appearance, timestamps and time-tagged CATS
+// names become constants and the CLI version banner is replaced.
+type OutputNormalizer struct {
+	guids map[string]string
+}
+
+func NewOutputNormalizer() *OutputNormalizer {
+	return &OutputNormalizer{guids: map[string]string{}}
+}
+
+func (n *OutputNormalizer) Normalize(text string) string {
+	text = cliVersionBannerPattern.ReplaceAllString(text, "${1}"+NormalizedCliVersion)
+
+	text = outputGuidPattern.ReplaceAllStringFunc(text, func(guid string) string {
+		guid = strings.ToLower(guid)
+		placeholder, ok := n.guids[guid]
+		if !ok {
+			placeholder = fmt.Sprintf("00000000-0000-0000-0000-%012d", len(n.guids)+1)
+			n.guids[guid] = placeholder
+		}
+		return placeholder
+	})
+
+	for _, pattern := range outputTimestampPatterns {
+		text = pattern.ReplaceAllString(text, NormalizedTimestamp)
+	}
+	text = outputTimeTagPattern.ReplaceAllString(text, NormalizedTimeTag)
+
+	return text
+}
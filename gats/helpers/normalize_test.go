@@ -0,0 +1,25 @@
+package helpers_test
+
+import (
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OutputNormalizer", func() {
+	It("numbers GUIDs in order of first appearance", func() {
+		normalizer := NewOutputNormalizer()
+		Expect(normalizer.Normalize("0A1B2C3D-1111-2222-3333-444455556666 99999999-aaaa-bbbb-cccc-ddddeeeeffff 0a1b2c3d-1111-2222-3333-444455556666")).To(Equal(
+			"00000000-0000-0000-0000-000000000001 00000000-0000-0000-0000-000000000002 00000000-0000-0000-0000-000000000001"))
+	})
+
+	It("replaces timestamps, time tags and the version banner", func() {
+		normalizer := NewOutputNormalizer()
+		Expect(normalizer.Normalize("cf version BUILT_FROM_SOURCE-BUILT_AT_UNKNOWN_TIME")).To(Equal("cf version {{cf-version}}"))
+		Expect(normalizer.Normalize("VERSION:\n   6.22.2+a95e24c-2016-10-27\n")).To(Equal("VERSION:\n   {{cf-version}}\n"))
+		Expect(normalizer.Normalize("since: Thu Oct 27 10:00:00 UTC 2016")).To(Equal("since: 2000-01-01T00:00:00Z"))
+		Expect(normalizer.Normalize("2016-10-27T10:00:00.00-0700 [API/0]")).To(Equal("2000-01-01T00:00:00Z [API/0]"))
+		Expect(normalizer.Normalize("CATS-ORG-1-0-2016_10_27-10h00m00.123s")).To(Equal("CATS-ORG-1-0-2000_01_01-00h00m00s"))
+	})
+})
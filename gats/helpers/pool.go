@@ -0,0 +1,303 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// poolUserPassword is the password of the users the pool creates, unless the
+// config sets test_password. It matches ConfiguredContext's.
+const poolUserPassword = "meow"
+
+// PoolBundle is an org, a space, a quota and a SpaceManager/Developer/Auditor
+// user created once per suite and leased to one spec at a time.
+type PoolBundle struct {
+	Node     int    `json:"node"`
+	Org      string `json:"org"`
+	Space    string `json:"space"`
+	Quota    string `json:"quota"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Pool hands out the bundles created for this parallel node. It replaces the
+// per-spec ConfiguredContext/Environment Setup and Teardown.
+type Pool struct {
+	config  Config
+	timeout time.Duration
+
+	all []PoolBundle
+
+	mutex sync.Mutex
+	free  []PoolBundle
+}
+
+// CreatePoolBundles creates config.PoolSize bundles for every parallel node.
+// Call it from the node 1 function of SynchronizedBeforeSuite and pass the
+// result on to NewPool.
+func CreatePoolBundles(config Config) []byte {
+	timeout := config.ScaledTimeout(1 * time.Minute)
+	bundles := poolBundles(config)
+
+	cf.AsUser(adminUserContext(config), timeout, func() {
+		for _, bundle := range bundles {
+			createPoolBundle(bundle, !config.UseExistingUser, timeout)
+		}
+	})
+
+	return encodePoolBundles(bundles)
+}
+
+// NamePoolBundles names the bundles CreatePoolBundles would create, without
+// creating them. It is for suites that replay recorded traffic, where the
+// bundles only exist in the recording.
+func NamePoolBundles(config Config) []byte {
+	return encodePoolBundles(poolBundles(config))
+}
+
+func poolBundles(config Config) []PoolBundle {
+	timeTag := time.Now().Format("2006_01_02-15h04m05.999s")
+
+	size := config.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	password := poolUserPassword
+	if config.UseExistingUser {
+		password = config.ExistingUserPassword
+	}
+	if config.ConfigurableTestPassword != "" {
+		password = config.ConfigurableTestPassword
+	}
+	RegisterSecret(password)
+
+	var bundles []PoolBundle
+	for node := 1; node <= ginkgoconfig.GinkgoConfig.ParallelTotal; node++ {
+		for i := 0; i < size; i++ {
+			username := fmt.Sprintf("CATS-USER-%d-%d-%s", node, i, timeTag)
+			if config.UseExistingUser {
+				username = config.ExistingUser
+			}
+
+			bundles = append(bundles, PoolBundle{
+				Node:     node,
+				Org:      fmt.Sprintf("CATS-ORG-%d-%d-%s", node, i, timeTag),
+				Space:    fmt.Sprintf("CATS-SPACE-%d-%d-%s", node, i, timeTag),
+				Quota:    fmt.Sprintf("CATS-QUOTA-%d-%d-%s", node, i, timeTag),
+				Username: username,
+				Password: password,
+			})
+		}
+	}
+
+	return bundles
+}
+
+func encodePoolBundles(bundles []PoolBundle) []byte {
+	encoded, err := json.Marshal(bundles)
+	Expect(err).NotTo(HaveOccurred())
+	return encoded
+}
+
+func createPoolBundle(bundle PoolBundle, createUser bool, timeout time.Duration) {
+	Eventually(cf.Cf("create-quota", bundle.Quota, "-m", "10G", "-r", "1000", "-s", "100", "--allow-paid-service-plans"), timeout).Should(Exit(0))
+
+	if createUser {
+		session := cf.Cf("create-user", bundle.Username, bundle.Password)
+		Eventually(session, timeout).Should(Exit())
+		if session.ExitCode() != 0 {
+			Expect(session.Out).To(Say("scim_resource_already_exists"))
+		}
+	}
+
+	Eventually(cf.Cf("create-org", bundle.Org), timeout).Should(Exit(0))
+	Eventually(cf.Cf("set-quota", bundle.Org, bundle.Quota), timeout).Should(Exit(0))
+	Eventually(cf.Cf("create-space", "-o", bundle.Org, bundle.Space), timeout).Should(Exit(0))
+
+	for _, role := range []string{"SpaceManager", "SpaceDeveloper", "SpaceAuditor"} {
+		Eventually(cf.Cf("set-space-role", bundle.Username, bundle.Org, bundle.Space, role), timeout).Should(Exit(0))
+	}
+}
+
+// NewPool decodes the bundles created by CreatePoolBundles and keeps the ones
+// that belong to this parallel node.
+func NewPool(config Config, encoded []byte) *Pool {
+	var all []PoolBundle
+	Expect(json.Unmarshal(encoded, &all)).To(Succeed())
+
+	pool := &Pool{
+		config:  config,
+		timeout: config.ScaledTimeout(1 * time.Minute),
+		all:     all,
+	}
+
+	for _, bundle := range all {
+		RegisterSecret(bundle.Password)
+		if bundle.Node == ginkgoconfig.GinkgoConfig.ParallelNode {
+			pool.free = append(pool.free, bundle)
+		}
+	}
+
+	return pool
+}
+
+// Available lists the bundles of this node that are not leased.
+func (p *Pool) Available() []PoolBundle {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]PoolBundle{}, p.free...)
+}
+
+// Lease takes a free bundle and logs its user in to a fresh CF_HOME, targeting
+// the bundle's org and space.
+func (p *Pool) Lease() *Lease {
+	bundle, ok := p.take()
+	ExpectWithOffset(1, ok).To(BeTrue(), "no free bundles left in the pool; raise pool_size")
+
+	lease := &Lease{pool: p, Bundle: bundle}
+	lease.originalCfHomeDir, lease.currentCfHomeDir = cf.InitiateUserContext(lease.RegularUserContext(), p.timeout)
+	cf.TargetSpace(lease.RegularUserContext(), p.timeout)
+
+	return lease
+}
+
+func (p *Pool) take() (PoolBundle, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.free) == 0 {
+		return PoolBundle{}, false
+	}
+
+	bundle := p.free[0]
+	p.free = p.free[1:]
+	return bundle, true
+}
+
+func (p *Pool) give(bundle PoolBundle) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.free = append(p.free, bundle)
+}
+
+// Destroy deletes every bundle of every node, except an existing user the
+// config named. Call it from the node 1 function of SynchronizedAfterSuite.
+func (p *Pool) Destroy() {
+	cf.AsUser(adminUserContext(p.config), p.timeout, func() {
+		for _, bundle := range p.all {
+			if !p.config.UseExistingUser && !p.config.ShouldKeepUser {
+				Eventually(cf.Cf("delete-user", "-f", bundle.Username), p.timeout).Should(Exit(0))
+			}
+			Eventually(cf.Cf("delete-org", "-f", bundle.Org), p.timeout).Should(Exit(0))
+			Eventually(cf.Cf("delete-quota", "-f", bundle.Quota), p.timeout).Should(Exit(0))
+		}
+	})
+}
+
+type Lease struct {
+	Bundle PoolBundle
+
+	pool              *Pool
+	originalCfHomeDir string
+	currentCfHomeDir  string
+}
+
+func (l *Lease) RegularUserContext() cf.UserContext {
+	return cf.NewUserContext(
+		l.pool.config.ApiEndpoint,
+		l.Bundle.Username,
+		l.Bundle.Password,
+		l.Bundle.Org,
+		l.Bundle.Space,
+		l.pool.config.SkipSSLValidation,
+	)
+}
+
+func (l *Lease) AdminUserContext() cf.UserContext {
+	return adminUserContext(l.pool.config)
+}
+
+// Release scrubs the apps, service instances and routes a spec left in the
+// bundle's space, restores CF_HOME and returns the bundle to the pool. The
+// bundle goes back and CF_HOME is restored even when scrubbing fails, so one
+// failed scrub doesn't fail every later lease on the node.
+func (l *Lease) Release() {
+	defer l.pool.give(l.Bundle)
+	defer cf.RestoreUserContext(l.RegularUserContext(), l.pool.timeout, l.originalCfHomeDir, l.currentCfHomeDir)
+
+	l.scrub()
+}
+
+// poolResource is a resource of a Cloud Controller listing. Entity.Type is
+// only set for service instances.
+type poolResource struct {
+	Metadata struct {
+		Guid string `json:"guid"`
+	} `json:"metadata"`
+	Entity struct {
+		Type string `json:"type"`
+	} `json:"entity"`
+}
+
+type poolResources struct {
+	NextURL   string         `json:"next_url"`
+	Resources []poolResource `json:"resources"`
+}
+
+func (l *Lease) scrub() {
+	spaceGuid := l.spaceGuid()
+
+	for _, app := range l.list(fmt.Sprintf("/v2/spaces/%s/apps?results-per-page=100", spaceGuid)) {
+		cf.ApiRequest("DELETE", fmt.Sprintf("/v2/apps/%s?recursive=true", app.Metadata.Guid), nil, l.pool.timeout)
+	}
+
+	for _, service := range l.list(fmt.Sprintf("/v2/spaces/%s/service_instances?return_user_provided_service_instances=true&results-per-page=100", spaceGuid)) {
+		endpoint := "/v2/service_instances/%s?recursive=true"
+		if service.Entity.Type == "user_provided_service_instance" {
+			endpoint = "/v2/user_provided_service_instances/%s"
+		}
+		cf.ApiRequest("DELETE", fmt.Sprintf(endpoint, service.Metadata.Guid), nil, l.pool.timeout)
+	}
+
+	for _, route := range l.list(fmt.Sprintf("/v2/spaces/%s/routes?results-per-page=100", spaceGuid)) {
+		cf.ApiRequest("DELETE", fmt.Sprintf("/v2/routes/%s?recursive=true", route.Metadata.Guid), nil, l.pool.timeout)
+	}
+}
+
+// list follows next_url through every page of a listing. It reads them all
+// before scrub deletes anything, so deleting doesn't move resources to pages
+// that were already read.
+func (l *Lease) list(endpoint string) []poolResource {
+	var resources []poolResource
+	for endpoint != "" {
+		var page poolResources
+		cf.ApiRequest("GET", endpoint, &page, l.pool.timeout)
+		resources = append(resources, page.Resources...)
+		endpoint = page.NextURL
+	}
+	return resources
+}
+
+func (l *Lease) spaceGuid() string {
+	cf.TargetSpace(l.RegularUserContext(), l.pool.timeout)
+
+	session := cf.Cf("space", l.Bundle.Space, "--guid").Wait(l.pool.timeout)
+	Expect(session).To(Exit(0))
+	return strings.TrimSpace(string(session.Out.Contents()))
+}
+
+func adminUserContext(config Config) cf.UserContext {
+	return cf.NewUserContext(config.ApiEndpoint, config.AdminUser, config.AdminPassword, "", "", config.SkipSSLValidation)
+}
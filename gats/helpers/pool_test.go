@@ -0,0 +1,123 @@
+package helpers_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pool", func() {
+	It("only hands out the bundles of the current parallel node", func() {
+		node := ginkgoconfig.GinkgoConfig.ParallelNode
+		encoded, err := json.Marshal([]PoolBundle{
+			{Node: node + 1, Org: "CATS-ORG-other"},
+			{Node: node, Org: "CATS-ORG-mine", Space: "CATS-SPACE-mine", Username: "CATS-USER-mine", Password: "meow"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := Config{}
+		config.ApiEndpoint = "api.example.com"
+		pool := NewPool(config, encoded)
+
+		Expect(pool.Available()).To(HaveLen(1))
+		Expect(pool.Available()[0].Org).To(Equal("CATS-ORG-mine"))
+	})
+
+	It("stays usable after a lease from an empty pool fails", func() {
+		config := Config{}
+		config.ApiEndpoint = "api.example.com"
+		pool := NewPool(config, []byte("[]"))
+
+		// Like Ginkgo's Fail, stop the lease at the failed assertion.
+		var failure string
+		func() {
+			defer RegisterFailHandler(Fail)
+			defer func() { recover() }()
+			RegisterFailHandler(func(message string, _ ...int) {
+				failure = message
+				panic(message)
+			})
+
+			pool.Lease()
+		}()
+
+		Expect(failure).To(ContainSubstring("no free bundles left in the pool"))
+		Expect(pool.Available()).To(BeEmpty())
+	})
+
+	It("names a bundle per parallel node without creating them", func() {
+		config := Config{}
+		config.ApiEndpoint = "api.example.com"
+		config.PoolSize = 2
+
+		var bundles []PoolBundle
+		Expect(json.Unmarshal(NamePoolBundles(config), &bundles)).To(Succeed())
+
+		Expect(bundles).To(HaveLen(2 * ginkgoconfig.GinkgoConfig.ParallelTotal))
+		Expect(bundles[0].Org).To(HavePrefix("CATS-ORG-1-0-"))
+		Expect(bundles[1].Space).To(HavePrefix("CATS-SPACE-1-1-"))
+		Expect(bundles[0].Username).To(HavePrefix("CATS-USER-1-0-"))
+		Expect(bundles[0].Password).To(Equal("meow"))
+	})
+
+	It("returns the bundle and restores CF_HOME when scrubbing fails", func() {
+		if runtime.GOOS == "windows" {
+			Skip("fakes cf with a shell script")
+		}
+
+		// A cf that logs in and targets, but can't look up the space, so
+		// the scrub fails before it deletes anything.
+		binDir, err := ioutil.TempDir("", "gats-pool")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(binDir)
+		script := "#!/bin/sh\n[ \"$1\" = space ] && exit 1\nexit 0\n"
+		Expect(ioutil.WriteFile(filepath.Join(binDir, "cf"), []byte(script), 0755)).To(Succeed())
+
+		originalPath := os.Getenv("PATH")
+		os.Setenv("PATH", binDir+string(os.PathListSeparator)+originalPath)
+		defer os.Setenv("PATH", originalPath)
+
+		originalCfHome := os.Getenv("CF_HOME")
+		defer os.Setenv("CF_HOME", originalCfHome)
+
+		node := ginkgoconfig.GinkgoConfig.ParallelNode
+		encoded, err := json.Marshal([]PoolBundle{{Node: node, Org: "CATS-ORG-mine", Space: "CATS-SPACE-mine", Username: "CATS-USER-mine", Password: "meow"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := Config{}
+		config.ApiEndpoint = "api.example.com"
+		config.TimeoutScale = 1
+		pool := NewPool(config, encoded)
+
+		lease := pool.Lease()
+		Expect(pool.Available()).To(BeEmpty())
+		leasedCfHome := os.Getenv("CF_HOME")
+
+		var failure string
+		func() {
+			defer RegisterFailHandler(Fail)
+			defer func() { recover() }()
+			RegisterFailHandler(func(message string, _ ...int) {
+				failure = message
+				panic(message)
+			})
+
+			lease.Release()
+		}()
+
+		Expect(failure).NotTo(BeEmpty())
+		Expect(pool.Available()).To(HaveLen(1))
+		Expect(os.Getenv("CF_HOME")).To(Equal(originalCfHome))
+		_, err = os.Stat(leasedCfHome)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
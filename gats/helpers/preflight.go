@@ -0,0 +1,351 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+)
+
+// MinimumCliVersion is the oldest cf release the suites are expected to pass against.
+const MinimumCliVersion = "6.14.0"
+
+const preflightTimeout = 30 * time.Second
+
+var errPreflightSkipped = errors.New("skipped")
+
+type PreflightOptions struct {
+	// GatsDirectory is the path of the gats directory; suites run from one
+	// level below it, the standalone doctor from the repository root.
+	GatsDirectory string
+}
+
+type PreflightResult struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+type PreflightReport struct {
+	Results []PreflightResult
+
+	// EffectiveConfig is the loaded config with secrets masked.
+	EffectiveConfig string
+}
+
+func (r PreflightReport) Failed() bool {
+	for _, result := range r.Results {
+		if result.Err != nil && result.Err != errPreflightSkipped {
+			return true
+		}
+	}
+	return false
+}
+
+func (r PreflightReport) String() string {
+	buffer := &bytes.Buffer{}
+	if r.Failed() {
+		fmt.Fprintln(buffer, "gats pre-flight check FAILED:")
+	} else {
+		fmt.Fprintln(buffer, "gats pre-flight check passed:")
+	}
+
+	for _, result := range r.Results {
+		switch {
+		case result.Err == errPreflightSkipped:
+			fmt.Fprintf(buffer, "  [skip] %s: %s\n", result.Name, result.Detail)
+		case result.Err != nil:
+			fmt.Fprintf(buffer, "  [FAIL] %s: %s\n", result.Name, result.Err)
+		default:
+			fmt.Fprintf(buffer, "  [ok]   %s: %s\n", result.Name, result.Detail)
+		}
+	}
+
+	if r.EffectiveConfig != "" {
+		fmt.Fprintf(buffer, "effective config:\n%s\n", r.EffectiveConfig)
+	}
+
+	return buffer.String()
+}
+
+type ccInfo struct {
+	APIVersion            string `json:"api_version"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+type preflight struct {
+	options PreflightOptions
+	report  PreflightReport
+	client  *http.Client
+
+	config *Config
+	info   *ccInfo
+}
+
+// Preflight validates the environment the suites are about to run in and
+// collects every problem into a single report instead of failing on the first.
+func Preflight(options PreflightOptions) PreflightReport {
+	p := &preflight{options: options}
+
+	p.check("config", p.checkConfig)
+
+	skipSSL := p.config != nil && p.config.SkipSSLValidation
+	p.client = &http.Client{
+		Timeout: preflightTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: skipSSL},
+		},
+	}
+
+	p.check("api reachable", p.checkInfo)
+	p.check("admin authentication", p.checkAdminAuth)
+	p.check("cf version", p.checkCliVersion)
+	p.check("apps domain", p.checkAppsDomain)
+	p.check("plugin fixture", p.checkPluginFixture)
+	p.check("assets", p.checkAssets)
+	p.check("artifacts directory", p.checkArtifactsDirectory)
+
+	return p.report
+}
+
+// ExpectPreflight fails the suite with the pre-flight report when any check failed.
+func ExpectPreflight() {
+	report := Preflight(PreflightOptions{GatsDirectory: ".."})
+	fmt.Fprint(GinkgoWriter, report.String())
+	if report.Failed() {
+		Fail(report.String())
+	}
+}
+
+func (p *preflight) check(name string, run func() (string, error)) {
+	detail, err := run()
+	p.report.Results = append(p.report.Results, PreflightResult{Name: name, Detail: detail, Err: err})
+}
+
+func (p *preflight) checkConfig() (string, error) {
+	path := os.Getenv("CONFIG")
+	if path == "" {
+		return "", errors.New("$CONFIG is not set; point it at a gats_config.json")
+	}
+
+	config, warnings, err := Load(path)
+	if err != nil {
+		return "", fmt.Errorf("loading %s: %s", path, err)
+	}
+
+	p.config = &config
+	p.report.EffectiveConfig = config.Masked()
+
+	if len(warnings) > 0 {
+		return fmt.Sprintf("%s (%s)", path, strings.Join(warnings, "; ")), nil
+	}
+	return path, nil
+}
+
+func (p *preflight) checkInfo() (string, error) {
+	if p.config == nil {
+		return "needs a valid config", errPreflightSkipped
+	}
+
+	candidates := []string{p.config.ApiEndpoint}
+	if !strings.HasPrefix(p.config.ApiEndpoint, "http") {
+		candidates = []string{"https://" + p.config.ApiEndpoint, "http://" + p.config.ApiEndpoint}
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		info := &ccInfo{}
+		lastErr = p.getJSON(candidate+"/v2/info", info)
+		if lastErr == nil {
+			p.info = info
+			return fmt.Sprintf("%s (api version %s)", candidate, info.APIVersion), nil
+		}
+	}
+
+	return "", lastErr
+}
+
+func (p *preflight) checkAdminAuth() (string, error) {
+	if p.info == nil {
+		return "needs a reachable api", errPreflightSkipped
+	}
+
+	tokenEndpoint := p.info.TokenEndpoint
+	if tokenEndpoint == "" {
+		tokenEndpoint = p.info.AuthorizationEndpoint
+	}
+
+	client, clientSecret := "cf", ""
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {p.config.AdminUser},
+		"password":   {p.config.AdminPassword},
+	}
+	if p.config.AdminClient != "" {
+		client, clientSecret = p.config.AdminClient, p.config.AdminClientSecret
+		form = url.Values{"grant_type": {"client_credentials"}}
+	}
+
+	request, err := http.NewRequest("POST", tokenEndpoint+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(client, clientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("UAA at %s rejected admin user %q (HTTP %d)", tokenEndpoint, p.config.AdminUser, response.StatusCode)
+	}
+
+	return fmt.Sprintf("%s via %s", p.config.AdminUser, tokenEndpoint), nil
+}
+
+func (p *preflight) checkCliVersion() (string, error) {
+	binary := "cf"
+	if p.config != nil {
+		binary = p.config.CfBinary()
+	}
+	if binary == "" {
+		return "", fmt.Errorf("%s=%s needs cf_binary_a and cf_binary_b in the config", ABSideEnvVar, os.Getenv(ABSideEnvVar))
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		if binary == "cf" {
+			return "", errors.New("no cf binary on PATH")
+		}
+		return "", fmt.Errorf("%s=%s selects %s: %s", ABSideEnvVar, os.Getenv(ABSideEnvVar), binary, err)
+	}
+
+	version, err := DetectCliVersion(path)
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := version.AtLeast(MinimumCliVersion)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%s is version %s, the suites need at least %s", path, version, MinimumCliVersion)
+	}
+
+	return fmt.Sprintf("%s is version %s", path, version), nil
+}
+
+func (p *preflight) checkAppsDomain() (string, error) {
+	if p.config == nil {
+		return "needs a valid config", errPreflightSkipped
+	}
+
+	if p.config.AppsDomain == "" {
+		return "", errors.New("apps_domain is not configured")
+	}
+
+	host := "gats-preflight." + p.config.AppsDomain
+	addresses, err := net.LookupHost(host)
+	if err != nil {
+		return "", fmt.Errorf("%s does not resolve: %s", host, err)
+	}
+
+	return fmt.Sprintf("%s resolves to %s", host, strings.Join(addresses, ", ")), nil
+}
+
+func (p *preflight) checkPluginFixture() (string, error) {
+	fixtures := filepath.Join(p.options.GatsDirectory, "plugin", "fixtures")
+
+	output, err := ioutil.TempDir("", "gats-preflight")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(output)
+
+	build := exec.Command("go", "build", "-o", filepath.Join(output, "plugin"), ".")
+	build.Dir = fixtures
+	buildOutput, err := build.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("`go build` in %s failed: %s\n%s", fixtures, err, buildOutput)
+	}
+
+	return fixtures + " builds", nil
+}
+
+func (p *preflight) checkAssets() (string, error) {
+	assets := AssetsIn(p.options.GatsDirectory)
+
+	var missing []string
+	for _, path := range []string{assets.ServiceBroker, assets.SecurityRules, assets.EmptySecurityRules, assets.DoraApp} {
+		_, err := os.Stat(path)
+		if err != nil {
+			missing = append(missing, path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	return "dora, service broker and security group assets present", nil
+}
+
+func (p *preflight) checkArtifactsDirectory() (string, error) {
+	if p.config == nil {
+		return "needs a valid config", errPreflightSkipped
+	}
+
+	directory := p.config.ArtifactsDirectory
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	probe, err := ioutil.TempFile(directory, "gats-preflight")
+	if err != nil {
+		return "", fmt.Errorf("%s is not writable: %s", directory, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return directory + " is writable", nil
+}
+
+func (p *preflight) getJSON(endpoint string, response interface{}) error {
+	request, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned HTTP %d", endpoint, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(response)
+}
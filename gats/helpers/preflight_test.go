@@ -0,0 +1,261 @@
+package helpers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preflight", func() {
+	type preflightCase struct {
+		description string
+
+		// setUp breaks one part of an environment that otherwise passes.
+		setUp func(server *standin.Server, config map[string]interface{}, binDir string)
+
+		// results maps check names to the start of the line the report
+		// prints for them.
+		results map[string]string
+	}
+
+	writeCf := func(path, version string) {
+		script := fmt.Sprintf("#!/bin/sh\necho 'cf version %s+6fd3c9f-2016-08-10'\n", version)
+		Expect(ioutil.WriteFile(path, []byte(script), 0755)).To(Succeed())
+	}
+
+	writeConfig := func(path string, config map[string]interface{}) {
+		contents, err := json.Marshal(config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(path, contents, 0600)).To(Succeed())
+	}
+
+	cases := []preflightCase{
+		{
+			"a healthy environment",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {},
+			map[string]string{
+				"config":               "[ok]   config: ",
+				"api reachable":        "[ok]   api reachable: ",
+				"admin authentication": "[ok]   admin authentication: " + standin.Username + " via ",
+				"cf version":           "[ok]   cf version: ",
+				"apps domain":          "[FAIL] apps domain: gats-preflight.gats.invalid does not resolve",
+				"assets":               "[ok]   assets: ",
+				"artifacts directory":  "[ok]   artifacts directory: ",
+			},
+		},
+		{
+			"a missing config",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				os.Unsetenv("CONFIG")
+			},
+			map[string]string{
+				"config":               "[FAIL] config: $CONFIG is not set",
+				"api reachable":        "[skip] api reachable: needs a valid config",
+				"admin authentication": "[skip] admin authentication: needs a reachable api",
+				"artifacts directory":  "[skip] artifacts directory: needs a valid config",
+			},
+		},
+		{
+			"an invalid config",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				delete(config, "api")
+			},
+			map[string]string{
+				"config":        "[FAIL] config: loading ",
+				"api reachable": "[skip] api reachable: needs a valid config",
+			},
+		},
+		{
+			"an API that fails /v2/info",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				server.Inject("GET", "/v2/info", standin.CCErrorFault(http.StatusServiceUnavailable, 10001, "CF-Unavailable", "down"))
+			},
+			map[string]string{
+				"api reachable":        "[FAIL] api reachable: GET {{api}}/v2/info returned HTTP 503",
+				"admin authentication": "[skip] admin authentication: needs a reachable api",
+			},
+		},
+		{
+			"UAA rejecting the admin user",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				config["admin_password"] = "gats-wrong-password"
+			},
+			map[string]string{
+				"api reachable":        "[ok]   api reachable: ",
+				"admin authentication": fmt.Sprintf("[FAIL] admin authentication: UAA at {{api}} rejected admin user %q (HTTP 401)", standin.Username),
+			},
+		},
+		{
+			"no cf on PATH",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				os.Remove(filepath.Join(binDir, "cf"))
+			},
+			map[string]string{
+				"cf version": "[FAIL] cf version: no cf binary on PATH",
+			},
+		},
+		{
+			"a cf older than the minimum",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				writeCf(filepath.Join(binDir, "cf"), "6.13.1")
+			},
+			map[string]string{
+				"cf version": "[FAIL] cf version: " + filepath.Join("{{bin}}", "cf") + " is version 6.13.1, the suites need at least " + MinimumCliVersion,
+			},
+		},
+		{
+			"the binary an A/B run selects instead of the cf on PATH",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				os.Remove(filepath.Join(binDir, "cf"))
+				writeCf(filepath.Join(binDir, "cf-a"), "6.21.1")
+				writeCf(filepath.Join(binDir, "cf-b"), "6.13.1")
+				config["cf_binary_a"] = filepath.Join(binDir, "cf-a")
+				config["cf_binary_b"] = filepath.Join(binDir, "cf-b")
+				os.Setenv(ABSideEnvVar, "b")
+			},
+			map[string]string{
+				"cf version": "[FAIL] cf version: " + filepath.Join("{{bin}}", "cf-b") + " is version 6.13.1, the suites need at least " + MinimumCliVersion,
+			},
+		},
+		{
+			"an A/B side without binaries",
+			func(server *standin.Server, config map[string]interface{}, binDir string) {
+				os.Setenv(ABSideEnvVar, "a")
+			},
+			map[string]string{
+				"cf version": "[FAIL] cf version: " + ABSideEnvVar + "=a needs cf_binary_a and cf_binary_b in the config",
+			},
+		},
+	}
+
+	var (
+		server    *standin.Server
+		directory string
+		binDir    string
+
+		originalConfig string
+		originalPath   string
+	)
+
+	BeforeEach(func() {
+		if runtime.GOOS == "windows" {
+			Skip("fakes cf with a shell script")
+		}
+
+		server = standin.New()
+
+		var err error
+		directory, err = ioutil.TempDir("", "gats-preflight")
+		Expect(err).NotTo(HaveOccurred())
+
+		// The cf check only sees binDir, so it can't pick up a real cf.
+		binDir = filepath.Join(directory, "bin")
+		Expect(os.Mkdir(binDir, 0755)).To(Succeed())
+		writeCf(filepath.Join(binDir, "cf"), "6.21.1")
+
+		// The plugin fixture check still needs go.
+		goBinary, err := exec.LookPath("go")
+		Expect(err).NotTo(HaveOccurred())
+
+		originalConfig = os.Getenv("CONFIG")
+		originalPath = os.Getenv("PATH")
+		os.Setenv("PATH", binDir+string(os.PathListSeparator)+filepath.Dir(goBinary))
+	})
+
+	AfterEach(func() {
+		if runtime.GOOS == "windows" {
+			return
+		}
+
+		os.Setenv("CONFIG", originalConfig)
+		os.Setenv("PATH", originalPath)
+		os.Unsetenv(ABSideEnvVar)
+		os.RemoveAll(directory)
+		server.Close()
+	})
+
+	for _, c := range cases {
+		c := c
+
+		It(fmt.Sprintf("reports %s", c.description), func() {
+			config := map[string]interface{}{
+				"api":                 server.URL(),
+				"admin_user":          standin.Username,
+				"admin_password":      standin.Password,
+				"apps_domain":         "gats.invalid",
+				"artifacts_directory": filepath.Join(directory, "results"),
+			}
+
+			path := filepath.Join(directory, "gats_config.json")
+			os.Setenv("CONFIG", path)
+			c.setUp(server, config, binDir)
+			writeConfig(path, config)
+
+			output := Preflight(PreflightOptions{GatsDirectory: ".."}).String()
+
+			for name, line := range c.results {
+				line = strings.Replace(line, "{{api}}", server.URL(), -1)
+				line = strings.Replace(line, "{{bin}}", binDir, -1)
+				Expect(output).To(ContainSubstring("  "+line), "the %s check in:\n%s", name, output)
+			}
+		})
+	}
+
+	It("masks secrets in the effective config it reports", func() {
+		path := filepath.Join(directory, "gats_config.json")
+		os.Setenv("CONFIG", path)
+		writeConfig(path, map[string]interface{}{
+			"api":            server.URL(),
+			"admin_user":     standin.Username,
+			"admin_password": standin.Password,
+		})
+
+		report := Preflight(PreflightOptions{GatsDirectory: ".."})
+		Expect(report.EffectiveConfig).NotTo(BeEmpty())
+		Expect(report.EffectiveConfig).NotTo(ContainSubstring(standin.Password))
+		Expect(report.String()).To(ContainSubstring("effective config:\n" + report.EffectiveConfig))
+	})
+})
+
+var _ = Describe("PreflightReport", func() {
+	It("passes when every check passed", func() {
+		report := PreflightReport{Results: []PreflightResult{
+			{Name: "config", Detail: "gats_config.json"},
+			{Name: "api reachable", Detail: "https://api.bosh-lite.com (api version 2.54.0)"},
+		}}
+
+		Expect(report.Failed()).To(BeFalse())
+		Expect(report.String()).To(Equal("gats pre-flight check passed:\n" +
+			"  [ok]   config: gats_config.json\n" +
+			"  [ok]   api reachable: https://api.bosh-lite.com (api version 2.54.0)\n"))
+	})
+
+	It("fails when any check failed", func() {
+		report := PreflightReport{
+			Results: []PreflightResult{
+				{Name: "config", Detail: "gats_config.json"},
+				{Name: "cf version", Err: errors.New("no cf binary on PATH")},
+			},
+			EffectiveConfig: `{"api": "api.bosh-lite.com"}`,
+		}
+
+		Expect(report.Failed()).To(BeTrue())
+		Expect(report.String()).To(Equal("gats pre-flight check FAILED:\n" +
+			"  [ok]   config: gats_config.json\n" +
+			"  [FAIL] cf version: no cf binary on PATH\n" +
+			"effective config:\n{\"api\": \"api.bosh-lite.com\"}\n"))
+	})
+})
@@ -0,0 +1,366 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	acceptanceTestHelpers "github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	"github.com/onsi/ginkgo"
+	ginkgoconfig "github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/reporters"
+	"github.com/onsi/ginkgo/types"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	gomegatypes "github.com/onsi/gomega/types"
+)
+
+const RedactedPlaceholder = "[REDACTED]"
+
+const timeFormat = "2006-01-02 15:04:05.00 (MST)"
+
+// secretArgs describes which arguments of a cf command carry secrets.
+// Positions are counted from zero after the command name and skip flags.
+type secretArgs struct {
+	positions []int
+	flags     []string
+}
+
+var secretCommandArgs = map[string]secretArgs{
+	"auth":                         {positions: []int{1}},
+	"login":                        {flags: []string{"-p"}},
+	"l":                            {flags: []string{"-p"}},
+	"create-user":                  {positions: []int{1}},
+	"create-service-broker":        {positions: []int{1, 2}},
+	"update-service-broker":        {positions: []int{1, 2}},
+	"create-user-provided-service": {flags: []string{"-p"}},
+	"cups":                         {flags: []string{"-p"}},
+	"update-user-provided-service": {flags: []string{"-p"}},
+	"uups":                         {flags: []string{"-p"}},
+	"create-service-auth-token":    {positions: []int{2}},
+	"update-service-auth-token":    {positions: []int{2}},
+	"set-env":                      {positions: []int{2}},
+	"se":                           {positions: []int{2}},
+}
+
+var bearerTokenPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-_\.=+/]+`)
+
+var (
+	secretsMutex sync.RWMutex
+	secretValues = map[string]struct{}{}
+)
+
+// RegisterSecret makes RedactText scrub every later occurrence of value.
+func RegisterSecret(value string) {
+	if value == "" {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secretValues[value] = struct{}{}
+}
+
+// RedactArgs returns a copy of the arguments of a cf invocation (without the
+// executable) with every secret argument replaced by RedactedPlaceholder. The
+// secret values are registered too, so RedactText also scrubs them from the
+// command's output, trace and failure messages.
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = RedactText(arg)
+	}
+
+	if len(args) == 0 {
+		return redacted
+	}
+
+	rules, ok := secretCommandArgs[args[0]]
+	if !ok {
+		return redacted
+	}
+
+	position := 0
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if strings.HasPrefix(arg, "-") {
+			name := arg
+			if idx := strings.Index(arg, "="); idx >= 0 {
+				name = arg[:idx]
+				if containsString(rules.flags, name) {
+					RegisterSecret(arg[idx+1:])
+					redacted[i] = name + "=" + RedactedPlaceholder
+				}
+				continue
+			}
+
+			if containsString(rules.flags, name) && i+1 < len(args) {
+				RegisterSecret(args[i+1])
+				redacted[i+1] = RedactedPlaceholder
+				i++
+			}
+			continue
+		}
+
+		if containsInt(rules.positions, position) {
+			RegisterSecret(arg)
+			redacted[i] = RedactedPlaceholder
+		}
+		position++
+	}
+
+	return redacted
+}
+
+// RedactText masks bearer tokens and every registered secret in s.
+func RedactText(s string) string {
+	s = bearerTokenPattern.ReplaceAllString(s, "${1}"+RedactedPlaceholder)
+
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for secret := range secretValues {
+		s = strings.Replace(s, secret, RedactedPlaceholder, -1)
+	}
+
+	return s
+}
+
+// RedactFile scrubs a trace or log artifact in place.
+func RedactFile(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, []byte(RedactText(string(contents))), 0600)
+}
+
+// RedactArtifacts scrubs every CF_TRACE file the suite wrote to the artifacts directory.
+func RedactArtifacts(artifactsDirectory string) error {
+	traces, err := filepath.Glob(filepath.Join(artifactsDirectory, "CATS-TRACE-*.txt"))
+	if err != nil {
+		return err
+	}
+
+	for _, trace := range traces {
+		err = RedactFile(trace)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type redactingWriter struct {
+	writer io.Writer
+}
+
+// NewRedactingWriter wraps writer so that everything written to it is passed through RedactText.
+func NewRedactingWriter(writer io.Writer) io.Writer {
+	return &redactingWriter{writer: writer}
+}
+
+func (w *redactingWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.writer, RedactText(string(p)))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+type RedactingReporter struct{}
+
+func NewRedactingReporter() *RedactingReporter {
+	return &RedactingReporter{}
+}
+
+func (r *RedactingReporter) Report(startTime time.Time, cmd *exec.Cmd) {
+	args := []string{cmd.Args[0]}
+	if filepath.Base(cmd.Args[0]) == "cf" || filepath.Base(cmd.Args[0]) == "cf.exe" {
+		args = append(args, RedactArgs(cmd.Args[1:])...)
+	} else {
+		for _, arg := range cmd.Args[1:] {
+			args = append(args, RedactText(arg))
+		}
+	}
+
+	startColor := ""
+	endColor := ""
+	if !ginkgoconfig.DefaultReporterConfig.NoColor {
+		startColor = "\x1b[32m"
+		endColor = "\x1b[0m"
+	}
+	fmt.Fprintf(ginkgo.GinkgoWriter, "\n%s[%s]> %s %s\n", startColor, startTime.UTC().Format(timeFormat), strings.Join(args, " "), endColor)
+}
+
+// StartRedacted starts executable like runner.Run does, but reports the command
+// line and forwards its output through the redaction layer.
+func StartRedacted(executable string, args ...string) *gexec.Session {
+	cmd := exec.Command(executable, args...)
+	NewRedactingReporter().Report(time.Now(), cmd)
+
+	output := NewRedactingWriter(ginkgo.GinkgoWriter)
+	sess, err := gexec.Start(runner.CommandInterceptor(cmd), output, output)
+	Expect(err).NotTo(HaveOccurred())
+
+	return sess
+}
+
+// InstallRedaction routes cf.Cf and cf.CfAuth through the redaction layer.
+func InstallRedaction() {
+	cf.Cf = func(args ...string) *gexec.Session {
+		return StartRedacted("cf", args...)
+	}
+
+	cf.CfAuth = func(user, password string) *gexec.Session {
+		RegisterSecret(password)
+		if isClientCredentialsUser(user) {
+			return StartRedacted("cf", "auth", user, password, "--client-credentials")
+		}
+		return StartRedacted("cf", "auth", user, password)
+	}
+}
+
+// RedactingFail wraps a fail handler so that matcher failure messages are scrubbed.
+func RedactingFail(fail gomegatypes.GomegaFailHandler) gomegatypes.GomegaFailHandler {
+	return func(message string, callerSkip ...int) {
+		skip := 0
+		if len(callerSkip) > 0 {
+			skip = callerSkip[0]
+		}
+
+		fail(RedactText(message), skip+1)
+	}
+}
+
+// RunRedactedSpecs runs a suite with every cf invocation and failure message
+// redacted. When $CONFIG loads, cf also traces into the artifacts directory
+// and a JUnit report is written there; once the suite has run, the traces are
+// scrubbed as well. Without a config, as for the stand-in suites, only the
+// console output is produced.
+func RunRedactedSpecs(t ginkgo.GinkgoTestingT, description, componentName string) bool {
+	RegisterFailHandler(RedactingFail(ginkgo.Fail))
+	InstallRedaction()
+
+	config, ok := artifactsConfig()
+	if !ok {
+		return ginkgo.RunSpecs(t, description)
+	}
+
+	if os.Getenv("CF_TRACE") == "" {
+		acceptanceTestHelpers.EnableCFTrace(config.Config, componentName)
+	}
+
+	passed := ginkgo.RunSpecsWithDefaultAndCustomReporters(t, description, []ginkgo.Reporter{
+		NewRedactingJUnitReporter(config.Config, componentName),
+	})
+
+	err := RedactArtifacts(config.ArtifactsDirectory)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "redacting artifacts:", err)
+		return false
+	}
+	return passed
+}
+
+// artifactsConfig loads $CONFIG for RunRedactedSpecs and makes sure its
+// artifacts directory exists. A missing or invalid config is left for the
+// pre-flight check to report.
+func artifactsConfig() (Config, bool) {
+	path := os.Getenv("CONFIG")
+	if path == "" {
+		return Config{}, false
+	}
+
+	config, _, err := Load(path)
+	if err != nil {
+		return Config{}, false
+	}
+
+	err = os.MkdirAll(config.ArtifactsDirectory, 0755)
+	if err != nil {
+		return Config{}, false
+	}
+
+	config.registerSecrets()
+	return config, true
+}
+
+type redactingSpecReporter struct {
+	reporter reporters.Reporter
+}
+
+// NewRedactingReporterFor scrubs failure messages and captured output before handing them to reporter.
+func NewRedactingReporterFor(reporter reporters.Reporter) reporters.Reporter {
+	return &redactingSpecReporter{reporter: reporter}
+}
+
+// NewRedactingJUnitReporter writes a JUnit report to the artifacts directory with secrets scrubbed.
+func NewRedactingJUnitReporter(config acceptanceTestHelpers.Config, componentName string) reporters.Reporter {
+	return NewRedactingReporterFor(acceptanceTestHelpers.NewJUnitReporter(config, componentName))
+}
+
+func (r *redactingSpecReporter) SpecSuiteWillBegin(config ginkgoconfig.GinkgoConfigType, summary *types.SuiteSummary) {
+	r.reporter.SpecSuiteWillBegin(config, summary)
+}
+
+func (r *redactingSpecReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {
+	redactSetupSummary(setupSummary)
+	r.reporter.BeforeSuiteDidRun(setupSummary)
+}
+
+func (r *redactingSpecReporter) SpecWillRun(specSummary *types.SpecSummary) {
+	r.reporter.SpecWillRun(specSummary)
+}
+
+func (r *redactingSpecReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	specSummary.Failure.Message = RedactText(specSummary.Failure.Message)
+	specSummary.Failure.ForwardedPanic = RedactText(specSummary.Failure.ForwardedPanic)
+	specSummary.CapturedOutput = RedactText(specSummary.CapturedOutput)
+	r.reporter.SpecDidComplete(specSummary)
+}
+
+func (r *redactingSpecReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {
+	redactSetupSummary(setupSummary)
+	r.reporter.AfterSuiteDidRun(setupSummary)
+}
+
+func (r *redactingSpecReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	r.reporter.SpecSuiteDidEnd(summary)
+}
+
+func redactSetupSummary(setupSummary *types.SetupSummary) {
+	setupSummary.Failure.Message = RedactText(setupSummary.Failure.Message)
+	setupSummary.Failure.ForwardedPanic = RedactText(setupSummary.Failure.ForwardedPanic)
+	setupSummary.CapturedOutput = RedactText(setupSummary.CapturedOutput)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
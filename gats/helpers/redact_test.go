@@ -0,0 +1,57 @@
+package helpers_test
+
+import (
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redaction", func() {
+	Describe("RedactArgs", func() {
+		It("redacts secret positional arguments", func() {
+			Expect(RedactArgs([]string{"create-user", "bob", "gats-user-secret"})).To(Equal([]string{"create-user", "bob", RedactedPlaceholder}))
+			Expect(RedactArgs([]string{"create-service-broker", "broker", "gats-broker-user", "gats-broker-pass", "http://broker"})).To(Equal(
+				[]string{"create-service-broker", "broker", RedactedPlaceholder, RedactedPlaceholder, "http://broker"},
+			))
+		})
+
+		It("skips flags when counting positions", func() {
+			Expect(RedactArgs([]string{"create-service-broker", "--space-scoped", "broker", "gats-broker-user", "gats-broker-pass", "http://broker"})).To(Equal(
+				[]string{"create-service-broker", "--space-scoped", "broker", RedactedPlaceholder, RedactedPlaceholder, "http://broker"},
+			))
+		})
+
+		It("redacts secret flag values", func() {
+			Expect(RedactArgs([]string{"login", "-a", "api.example.com", "-u", "admin", "-p", "s3cret"})).To(Equal(
+				[]string{"login", "-a", "api.example.com", "-u", "admin", "-p", RedactedPlaceholder},
+			))
+			Expect(RedactArgs([]string{"cups", "my-service", "-p", `{"password":"s3cret"}`})).To(Equal(
+				[]string{"cups", "my-service", "-p", RedactedPlaceholder},
+			))
+			Expect(RedactArgs([]string{"login", "-p=s3cret"})).To(Equal([]string{"login", "-p=" + RedactedPlaceholder}))
+		})
+
+		It("registers the values it masks", func() {
+			RedactArgs([]string{"create-user", "bob", "gats-registered-secret"})
+			RedactArgs([]string{"login", "-p=gats-registered-flag-secret"})
+
+			Expect(RedactText("gats-registered-secret gats-registered-flag-secret")).To(Equal(RedactedPlaceholder + " " + RedactedPlaceholder))
+		})
+
+		It("leaves other commands untouched", func() {
+			Expect(RedactArgs([]string{"target", "-o", "org"})).To(Equal([]string{"target", "-o", "org"}))
+		})
+	})
+
+	Describe("RedactText", func() {
+		It("masks bearer tokens", func() {
+			Expect(RedactText("Done AccessToken: bearer eyJhbGciOi.eyJqdGki.c2lnbmF0dXJl\n")).To(Equal("Done AccessToken: bearer " + RedactedPlaceholder + "\n"))
+		})
+
+		It("masks registered secrets", func() {
+			RegisterSecret("hunter2")
+			Expect(RedactText("password was hunter2")).To(Equal("password was " + RedactedPlaceholder))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	envSecretPrefix  = "env:"
+	fileSecretPrefix = "file:"
+)
+
+var (
+	clientCredentialsMutex sync.RWMutex
+	clientCredentialsUsers = map[string]bool{}
+)
+
+// resolveSecret dereferences `env:VAR` and `file:/path` indirections. Errors
+// name the reference, never the value behind it.
+func resolveSecret(key, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envSecretPrefix):
+		name := strings.TrimPrefix(value, envSecretPrefix)
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("'%s' refers to environment variable %s, which is not set", key, name)
+		}
+		return resolved, nil
+
+	case strings.HasPrefix(value, fileSecretPrefix):
+		path := strings.TrimPrefix(value, fileSecretPrefix)
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("'%s' refers to file %s, which cannot be read", key, path)
+		}
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	}
+
+	return value, nil
+}
+
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, envSecretPrefix) || strings.HasPrefix(value, fileSecretPrefix)
+}
+
+// resolveSecrets replaces every secret reference in config with the value it
+// points to and remembers where each came from, so Masked can say so.
+func resolveSecrets(config *Config) ConfigErrors {
+	var errs ConfigErrors
+
+	config.secretSources = map[string]string{}
+	eachConfigField(config, func(key string, field reflect.Value) {
+		if !secretConfigKeys[key] || !isSecretReference(field.String()) {
+			return
+		}
+
+		reference := field.String()
+		resolved, err := resolveSecret(key, reference)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+
+		config.secretSources[key] = reference
+		field.SetString(resolved)
+	})
+
+	return errs
+}
+
+// useAdminClientCredentials makes the admin identity a UAA client instead of
+// a password user: cf-test-helpers contexts keep authenticating "as admin",
+// and CfAuth adds --client-credentials for that identity.
+func useAdminClientCredentials(config *Config) {
+	if config.AdminClient == "" {
+		return
+	}
+
+	config.AdminUser = config.AdminClient
+	config.AdminPassword = config.AdminClientSecret
+
+	clientCredentialsMutex.Lock()
+	defer clientCredentialsMutex.Unlock()
+	clientCredentialsUsers[config.AdminClient] = true
+}
+
+func isClientCredentialsUser(user string) bool {
+	clientCredentialsMutex.RLock()
+	defer clientCredentialsMutex.RUnlock()
+	return clientCredentialsUsers[user]
+}
@@ -0,0 +1,92 @@
+package interactive_test
+
+import (
+	"encoding/json"
+	"net/url"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// userProvidedCredentials reads the credentials of the user-provided service
+// instance name from the Cloud Controller.
+func userProvidedCredentials(name string) map[string]interface{} {
+	session := Cf("curl", "/v2/user_provided_service_instances?q="+url.QueryEscape("name:"+name)).Wait(commandTimeout)
+	Expect(session).To(Exit(0))
+
+	var instances struct {
+		Resources []struct {
+			Entity struct {
+				Credentials map[string]interface{} `json:"credentials"`
+			} `json:"entity"`
+		} `json:"resources"`
+	}
+	Expect(json.Unmarshal(session.Out.Contents(), &instances)).To(Succeed())
+	Expect(instances.Resources).To(HaveLen(1), "no user-provided service instance %s", name)
+
+	return instances.Resources[0].Entity.Credentials
+}
+
+var _ = Describe("cf create-user-provided-service -p with parameter names", func() {
+	var (
+		lease *gatsHelpers.Lease
+
+		restoreColor func()
+	)
+
+	BeforeEach(func() {
+		lease = pool.Lease()
+		restoreColor = disableColor()
+	})
+
+	AfterEach(func() {
+		restoreColor()
+		lease.Release()
+	})
+
+	It("prompts for each parameter and stores the answers as credentials", func() {
+		serviceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+
+		session := pty.Cf("create-user-provided-service", serviceName, "-p", "username, password, uri")
+		session.Answer("username", "gats-user", commandTimeout)
+		session.Answer("password", "gats secret with spaces", commandTimeout)
+		session.Answer("uri", "gats://interactive", commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(userProvidedCredentials(serviceName)).To(Equal(map[string]interface{}{
+			"username": "gats-user",
+			"password": "gats secret with spaces",
+			"uri":      "gats://interactive",
+		}))
+	})
+
+	It("stores an empty answer as an empty credential", func() {
+		serviceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+
+		session := pty.Cf("create-user-provided-service", serviceName, "-p", "username, password")
+		session.Answer("username", "gats-user", commandTimeout)
+		session.Answer("password", "", commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(userProvidedCredentials(serviceName)).To(Equal(map[string]interface{}{
+			"username": "gats-user",
+			"password": "",
+		}))
+	})
+
+	It("doesn't prompt when -p is JSON", func() {
+		serviceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+
+		session := pty.Cf("create-user-provided-service", serviceName, "-p", `{"username":"gats-user"}`)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(session.Transcript()).NotTo(ContainSubstring("> "))
+		Expect(userProvidedCredentials(serviceName)).To(Equal(map[string]interface{}{"username": "gats-user"}))
+	})
+})
@@ -0,0 +1,177 @@
+package interactive_test
+
+import (
+	"encoding/json"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("delete and purge confirmations", func() {
+	var (
+		config gatsHelpers.Config
+		lease  *gatsHelpers.Lease
+
+		restoreColor func()
+		appTimeout   time.Duration
+	)
+
+	BeforeEach(func() {
+		config = gatsHelpers.LoadConfig()
+		appTimeout = config.ScaledTimeout(5 * time.Minute)
+		lease = pool.Lease()
+		restoreColor = disableColor()
+	})
+
+	AfterEach(func() {
+		restoreColor()
+		lease.Release()
+	})
+
+	// confirm runs args on a terminal, answers prompt with answer and
+	// expects cf to exit 0 either way.
+	confirm := func(prompt, answer string, args ...string) *pty.Session {
+		session := pty.Cf(args...)
+		session.Answer(prompt, answer, commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+		return session
+	}
+
+	It("deletes an app only after a yes", func() {
+		appName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+		Eventually(Cf("push", appName, "-p", gatsHelpers.NewAssets().DoraApp, "--no-start"), appTimeout).Should(Exit(0))
+
+		prompt := "Really delete the app " + appName + "?"
+
+		session := confirm(prompt, "n", "delete", appName)
+		Expect(session).To(Say("Delete cancelled"))
+		Eventually(Cf("app", appName), commandTimeout).Should(Exit(0))
+
+		confirm(prompt, "y", "delete", appName)
+		Eventually(Cf("app", appName), commandTimeout).Should(Exit(1))
+	})
+
+	It("deletes a route only after a yes", func() {
+		host := generator.PrefixedRandomName("gats-interactive-")
+		Eventually(Cf("create-route", lease.Bundle.Space, config.AppsDomain, "-n", host), commandTimeout).Should(Exit(0))
+
+		prompt := "Really delete the route " + host + "." + config.AppsDomain + "?"
+
+		session := confirm(prompt, "", "delete-route", config.AppsDomain, "-n", host)
+		Expect(session).To(Say("Delete cancelled"))
+		Expect(string(Cf("routes").Wait(commandTimeout).Out.Contents())).To(ContainSubstring(host))
+
+		confirm(prompt, "yes", "delete-route", config.AppsDomain, "-n", host)
+		Expect(string(Cf("routes").Wait(commandTimeout).Out.Contents())).NotTo(ContainSubstring(host))
+	})
+
+	It("deletes a service instance only after a yes", func() {
+		serviceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+		Eventually(Cf("create-user-provided-service", serviceName), commandTimeout).Should(Exit(0))
+
+		prompt := "Really delete the service " + serviceName + "?"
+
+		session := confirm(prompt, "n", "delete-service", serviceName)
+		Expect(session).To(Say("Delete cancelled"))
+		Eventually(Cf("service", serviceName), commandTimeout).Should(Exit(0))
+
+		confirm(prompt, "y", "delete-service", serviceName)
+		Eventually(Cf("service", serviceName), commandTimeout).Should(Exit(1))
+	})
+
+	It("deletes a space and an org only after a yes", func() {
+		orgName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+		spaceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+
+		asAdmin(lease, func() {
+			Eventually(Cf("create-org", orgName), commandTimeout).Should(Exit(0))
+			defer func() {
+				Eventually(Cf("delete-org", "-f", orgName), commandTimeout).Should(Exit(0))
+			}()
+			Eventually(Cf("create-space", spaceName, "-o", orgName), commandTimeout).Should(Exit(0))
+			Eventually(Cf("target", "-o", orgName), commandTimeout).Should(Exit(0))
+
+			spacePrompt := "Really delete the space " + spaceName + "?"
+			session := confirm(spacePrompt, "n", "delete-space", spaceName)
+			Expect(session).To(Say("Delete cancelled"))
+			Eventually(Cf("space", spaceName), commandTimeout).Should(Exit(0))
+
+			confirm(spacePrompt, "y", "delete-space", spaceName)
+			Eventually(Cf("space", spaceName), commandTimeout).Should(Exit(1))
+
+			orgPrompt := "Really delete the org " + orgName + " and everything associated with it?"
+			session = confirm(orgPrompt, "n", "delete-org", orgName)
+			Expect(session).To(Say("Delete cancelled"))
+			Eventually(Cf("org", orgName), commandTimeout).Should(Exit(0))
+
+			confirm(orgPrompt, "y", "delete-org", orgName)
+			Eventually(Cf("org", orgName), commandTimeout).Should(Exit(1))
+		})
+	})
+
+	// Purging skips the service broker and can't be undone, so only the
+	// prompt and declining it are exercised.
+	It("doesn't purge a service instance when the purge is declined", func() {
+		serviceName := generator.PrefixedRandomName("GATS-INTERACTIVE-")
+		Eventually(Cf("create-user-provided-service", serviceName), commandTimeout).Should(Exit(0))
+
+		asAdmin(lease, func() {
+			Eventually(Cf("target", "-o", lease.Bundle.Org, "-s", lease.Bundle.Space), commandTimeout).Should(Exit(0))
+
+			session := pty.Cf("purge-service-instance", serviceName)
+			Eventually(session, commandTimeout).Should(Say("WARNING: This operation assumes"))
+			session.Answer("Really purge service instance "+serviceName+" from Cloud Foundry?", "n", commandTimeout)
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session.Transcript()).NotTo(ContainSubstring("Purging service"))
+			Eventually(Cf("service", serviceName), commandTimeout).Should(Exit(0))
+		})
+	})
+
+	It("doesn't purge a service offering when the purge is declined", func() {
+		asAdmin(lease, func() {
+			labels := serviceOfferingLabels()
+			if len(labels) == 0 {
+				Skip("needs a service offering in the marketplace")
+			}
+
+			session := pty.Cf("purge-service-offering", labels[0])
+			Eventually(session, commandTimeout).Should(Say("WARNING: This operation assumes"))
+			session.Answer("Really purge service offering "+labels[0]+" from Cloud Foundry?", "n", commandTimeout)
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session.Transcript()).NotTo(ContainSubstring("Purging service"))
+			Expect(serviceOfferingLabels()).To(ContainElement(labels[0]))
+		})
+	})
+})
+
+// serviceOfferingLabels lists the labels of the first page of service
+// offerings.
+func serviceOfferingLabels() []string {
+	session := Cf("curl", "/v2/services").Wait(commandTimeout)
+	Expect(session).To(Exit(0))
+
+	var services struct {
+		Resources []struct {
+			Entity struct {
+				Label string `json:"label"`
+			} `json:"entity"`
+		} `json:"resources"`
+	}
+	Expect(json.Unmarshal(session.Out.Contents(), &services)).To(Succeed())
+
+	var labels []string
+	for _, resource := range services.Resources {
+		labels = append(labels, resource.Entity.Label)
+	}
+	return labels
+}
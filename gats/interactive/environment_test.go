@@ -0,0 +1,37 @@
+package interactive_test
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+)
+
+const commandTimeout = 1 * time.Minute
+
+var _ = BeforeEach(func() {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		Skip("needs a pseudo-terminal, which gats/pty doesn't support on " + runtime.GOOS)
+	}
+})
+
+// disableColor stops cf from coloring its output, which it does on a
+// terminal and which would split prompts from their "> ". It returns a func
+// that restores the environment.
+func disableColor() func() {
+	original := os.Getenv("CF_COLOR")
+	os.Setenv("CF_COLOR", "false")
+
+	return func() {
+		os.Setenv("CF_COLOR", original)
+	}
+}
+
+// asAdmin runs actions as the admin user in a temporary CF_HOME.
+func asAdmin(lease *gatsHelpers.Lease, actions func()) {
+	AsUser(lease.AdminUserContext(), commandTimeout, actions)
+}
@@ -0,0 +1,29 @@
+package interactive_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+
+	. "github.com/onsi/ginkgo"
+
+	"testing"
+)
+
+var pool *gatsHelpers.Pool
+
+var _ = strict.RegisterHooks()
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	gatsHelpers.ExpectPreflight()
+	return gatsHelpers.CreatePoolBundles(gatsHelpers.LoadConfig())
+}, func(bundles []byte) {
+	pool = gatsHelpers.NewPool(gatsHelpers.LoadConfig(), bundles)
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	pool.Destroy()
+})
+
+func TestInteractive(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Interactive Suite", "interactive")
+}
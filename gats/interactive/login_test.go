@@ -0,0 +1,143 @@
+package interactive_test
+
+import (
+	"regexp"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("cf login", func() {
+	var (
+		config gatsHelpers.Config
+		lease  *gatsHelpers.Lease
+
+		restoreCfHome func()
+		restoreColor  func()
+	)
+
+	BeforeEach(func() {
+		config = gatsHelpers.LoadConfig()
+		lease = pool.Lease()
+		restoreColor = disableColor()
+
+		// Login starts from an empty CF_HOME, so cf asks for everything.
+		_, restoreCfHome = gatsHelpers.UseTempCfHome("gats-interactive")
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		restoreColor()
+		lease.Release()
+	})
+
+	login := func(args ...string) *pty.Session {
+		args = append([]string{"login"}, args...)
+		if config.SkipSSLValidation {
+			args = append(args, "--skip-ssl-validation")
+		}
+		return pty.Cf(args...)
+	}
+
+	It("prompts for the API endpoint and the credentials", func() {
+		session := login()
+
+		session.Answer("API endpoint", config.ApiEndpoint, commandTimeout)
+		session.Answer("Email", lease.Bundle.Username, commandTimeout)
+		session.Answer("Password", lease.Bundle.Password, commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(session.Transcript()).To(ContainSubstring("Targeted org " + lease.Bundle.Org))
+		Expect(session.Transcript()).To(ContainSubstring("Targeted space " + lease.Bundle.Space))
+
+		current := gatsHelpers.CurrentCfConfig()
+		Expect(current.OrganizationFields.Name).To(Equal(lease.Bundle.Org))
+		Expect(current.SpaceFields.Name).To(Equal(lease.Bundle.Space))
+	})
+
+	It("doesn't echo the password", func() {
+		session := login("-a", config.ApiEndpoint, "-u", lease.Bundle.Username)
+
+		session.Answer("Password", lease.Bundle.Password, commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(session.Transcript()).NotTo(ContainSubstring(lease.Bundle.Password))
+	})
+
+	It("asks for the password again when it is rejected", func() {
+		session := login("-a", config.ApiEndpoint, "-u", lease.Bundle.Username)
+
+		session.Answer("Password", "gats-wrong-password", commandTimeout)
+		Eventually(session, commandTimeout).Should(Say("Credentials were rejected, please try again."))
+		session.Answer("Password", lease.Bundle.Password, commandTimeout)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(gatsHelpers.CurrentCfConfig().OrganizationFields.Name).To(Equal(lease.Bundle.Org))
+	})
+
+	Context("when the user is a member of several orgs", func() {
+		var otherOrg string
+
+		BeforeEach(func() {
+			otherOrg = generator.PrefixedRandomName("GATS-INTERACTIVE-")
+
+			asAdmin(lease, func() {
+				Eventually(Cf("create-org", otherOrg), commandTimeout).Should(Exit(0))
+				Eventually(Cf("set-org-role", lease.Bundle.Username, otherOrg, "OrgManager"), commandTimeout).Should(Exit(0))
+			})
+		})
+
+		AfterEach(func() {
+			asAdmin(lease, func() {
+				Eventually(Cf("delete-org", "-f", otherOrg), commandTimeout).Should(Exit(0))
+			})
+		})
+
+		It("lists the orgs and targets the one picked by number", func() {
+			session := login("-a", config.ApiEndpoint, "-u", lease.Bundle.Username, "-p", lease.Bundle.Password)
+
+			Eventually(session, commandTimeout).Should(Say(`Select an org \(or press enter to skip\):`))
+			Eventually(session, commandTimeout).Should(Say("Org> "))
+
+			choice := regexp.MustCompile(`(?m)^(\d+)\. ` + regexp.QuoteMeta(otherOrg) + `$`).FindStringSubmatch(session.Transcript())
+			Expect(choice).NotTo(BeNil(), "%s isn't in the list of orgs", otherOrg)
+			Expect(session.Transcript()).To(MatchRegexp(`(?m)^\d+\. %s$`, regexp.QuoteMeta(lease.Bundle.Org)))
+
+			Expect(session.SendLine(choice[1])).To(Succeed())
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session.Transcript()).To(ContainSubstring("Targeted org " + otherOrg))
+			Expect(gatsHelpers.CurrentCfConfig().OrganizationFields.Name).To(Equal(otherOrg))
+		})
+
+		It("targets the org typed by name", func() {
+			session := login("-a", config.ApiEndpoint, "-u", lease.Bundle.Username, "-p", lease.Bundle.Password)
+
+			session.Answer("Org", lease.Bundle.Org, commandTimeout)
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			current := gatsHelpers.CurrentCfConfig()
+			Expect(current.OrganizationFields.Name).To(Equal(lease.Bundle.Org))
+			Expect(current.SpaceFields.Name).To(Equal(lease.Bundle.Space))
+		})
+
+		It("targets no org when the selection is skipped", func() {
+			session := login("-a", config.ApiEndpoint, "-u", lease.Bundle.Username, "-p", lease.Bundle.Password)
+
+			session.Answer("Org", "", commandTimeout)
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			current := gatsHelpers.CurrentCfConfig()
+			Expect(current.AccessToken).NotTo(BeEmpty())
+			Expect(current.OrganizationFields.GUID).To(BeEmpty())
+			Expect(current.SpaceFields.GUID).To(BeEmpty())
+		})
+	})
+})
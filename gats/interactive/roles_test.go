@@ -0,0 +1,58 @@
+package interactive_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// The cf 6 unset-*-role commands don't ask for confirmation; these specs
+// make sure they never wait on a terminal for one.
+var _ = Describe("cf unset-org-role and unset-space-role", func() {
+	var (
+		lease *gatsHelpers.Lease
+
+		restoreColor func()
+	)
+
+	BeforeEach(func() {
+		lease = pool.Lease()
+		restoreColor = disableColor()
+	})
+
+	AfterEach(func() {
+		restoreColor()
+		lease.Release()
+	})
+
+	It("removes an org role without prompting", func() {
+		asAdmin(lease, func() {
+			Eventually(Cf("set-org-role", lease.Bundle.Username, lease.Bundle.Org, "BillingManager"), commandTimeout).Should(Exit(0))
+
+			session := pty.Cf("unset-org-role", lease.Bundle.Username, lease.Bundle.Org, "BillingManager")
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session.Transcript()).NotTo(ContainSubstring("> "))
+			Expect(session.Transcript()).To(ContainSubstring("OK"))
+		})
+	})
+
+	It("removes a space role without prompting", func() {
+		asAdmin(lease, func() {
+			// The bundle's user keeps its roles for the next lease.
+			defer func() {
+				Eventually(Cf("set-space-role", lease.Bundle.Username, lease.Bundle.Org, lease.Bundle.Space, "SpaceAuditor"), commandTimeout).Should(Exit(0))
+			}()
+
+			session := pty.Cf("unset-space-role", lease.Bundle.Username, lease.Bundle.Org, lease.Bundle.Space, "SpaceAuditor")
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session.Transcript()).NotTo(ContainSubstring("> "))
+			Expect(session.Transcript()).To(ContainSubstring("OK"))
+		})
+	})
+})
@@ -0,0 +1,245 @@
+package pagination_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const commandTimeout = 2 * time.Minute
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+var (
+	orgCount     = envInt("GATS_PAGINATION_ORGS", 2500)
+	spaceCount   = envInt("GATS_PAGINATION_SPACES", 750)
+	appCount     = envInt("GATS_PAGINATION_APPS", 1200)
+	serviceCount = envInt("GATS_PAGINATION_SERVICES", 600)
+	userCount    = envInt("GATS_PAGINATION_USERS", 1100)
+	perPage      = envInt("GATS_PAGINATION_PER_PAGE", 50)
+)
+
+func guid(kind, i int) string {
+	return fmt.Sprintf("00000000-0000-0000-%04d-%012d", kind, i)
+}
+
+// The stand-in's own org and space come first, so the CLI can target them
+// among the generated ones.
+func organizations() []interface{} {
+	quota := standin.Resource("00000000-0000-0000-0000-00000000a004", map[string]interface{}{"name": "default"})
+
+	resources := []interface{}{
+		standin.Resource(standin.OrgGuid, map[string]interface{}{"name": standin.OrgName, "quota_definition": quota}),
+	}
+	for i := 0; i < orgCount; i++ {
+		resources = append(resources, standin.Resource(guid(1, i), map[string]interface{}{
+			"name":             fmt.Sprintf("gats-page-org-%05d", i),
+			"quota_definition": quota,
+		}))
+	}
+	return resources
+}
+
+func spaces() []interface{} {
+	resources := []interface{}{
+		standin.Resource(standin.SpaceGuid, map[string]interface{}{"name": standin.SpaceName, "organization_guid": standin.OrgGuid, "allow_ssh": true}),
+	}
+	for i := 0; i < spaceCount; i++ {
+		resources = append(resources, standin.Resource(guid(2, i), map[string]interface{}{
+			"name":              fmt.Sprintf("gats-page-space-%05d", i),
+			"organization_guid": standin.OrgGuid,
+			"allow_ssh":         true,
+		}))
+	}
+	return resources
+}
+
+func users() []interface{} {
+	var resources []interface{}
+	for i := 0; i < userCount; i++ {
+		resources = append(resources, standin.Resource(guid(3, i), map[string]interface{}{
+			"username": fmt.Sprintf("gats-page-user-%05d", i),
+			"admin":    false,
+		}))
+	}
+	return resources
+}
+
+// spaceSummary is unpaginated: the Cloud Controller returns every app and
+// service instance of the space in one response.
+func spaceSummary() map[string]interface{} {
+	var apps []interface{}
+	for i := 0; i < appCount; i++ {
+		apps = append(apps, map[string]interface{}{
+			"guid":              guid(4, i),
+			"name":              fmt.Sprintf("gats-page-app-%05d", i),
+			"state":             "STARTED",
+			"instances":         1,
+			"running_instances": 1,
+			"memory":            256,
+			"disk_quota":        1024,
+			"urls":              []string{},
+			"routes":            []interface{}{},
+			"service_names":     []string{},
+		})
+	}
+
+	var services []interface{}
+	for i := 0; i < serviceCount; i++ {
+		service := map[string]interface{}{
+			"guid":            guid(5, i),
+			"name":            fmt.Sprintf("gats-page-service-%05d", i),
+			"bound_app_count": 0,
+			"last_operation":  map[string]interface{}{"type": "create", "state": "succeeded"},
+		}
+		// Every third instance is user-provided, which has no plan.
+		if i%3 != 0 {
+			service["service_plan"] = map[string]interface{}{
+				"guid":    guid(6, 0),
+				"name":    "gats-plan",
+				"service": map[string]interface{}{"guid": guid(7, 0), "label": "gats-service", "provider": "", "version": ""},
+			}
+		}
+		services = append(services, service)
+	}
+
+	return map[string]interface{}{
+		"guid":     standin.SpaceGuid,
+		"name":     standin.SpaceName,
+		"apps":     apps,
+		"services": services,
+	}
+}
+
+func pages(count int) int {
+	return (count + perPage - 1) / perPage
+}
+
+// listing is a command that lists every resource of one kind.
+type listing struct {
+	args []string
+
+	// pattern matches the name of every generated resource in the output.
+	pattern string
+	count   int
+
+	// path is the list the command walks, and pages how many requests for it
+	// walking every page exactly once takes.
+	path  string
+	pages int
+}
+
+var listings = []listing{
+	{args: []string{"orgs"}, pattern: `gats-page-org-\d{5}`, count: orgCount, path: "/v2/organizations", pages: pages(orgCount + 1)},
+	{args: []string{"spaces"}, pattern: `gats-page-space-\d{5}`, count: spaceCount, path: "/v2/organizations/" + standin.OrgGuid + "/spaces", pages: pages(spaceCount + 1)},
+	{args: []string{"apps"}, pattern: `gats-page-app-\d{5}`, count: appCount, path: "/v2/spaces/" + standin.SpaceGuid + "/summary", pages: 1},
+	{args: []string{"services"}, pattern: `gats-page-service-\d{5}`, count: serviceCount, path: "/v2/spaces/" + standin.SpaceGuid + "/summary", pages: 1},
+	{args: []string{"org-users", standin.OrgName, "-a"}, pattern: `gats-page-user-\d{5}`, count: userCount, path: "/v2/organizations/" + standin.OrgGuid + "/users", pages: pages(userCount)},
+
+	{args: []string{"GetOrgs"}, pattern: `gats-page-org-\d{5}`, count: orgCount, path: "/v2/organizations", pages: pages(orgCount + 1)},
+	{args: []string{"GetSpaces"}, pattern: `gats-page-space-\d{5}`, count: spaceCount, path: "/v2/organizations/" + standin.OrgGuid + "/spaces", pages: pages(spaceCount + 1)},
+	{args: []string{"GetApps"}, pattern: `gats-page-app-\d{5}`, count: appCount, path: "/v2/spaces/" + standin.SpaceGuid + "/summary", pages: 1},
+	{args: []string{"GetServices"}, pattern: `gats-page-service-\d{5}`, count: serviceCount, path: "/v2/spaces/" + standin.SpaceGuid + "/summary", pages: 1},
+	{args: []string{"GetOrgUsers", standin.OrgName, "-a"}, pattern: `gats-page-user-\d{5}`, count: userCount, path: "/v2/organizations/" + standin.OrgGuid + "/users", pages: pages(userCount)},
+}
+
+// duplicatesAndMissing counts every name matching pattern in output and
+// lists the names seen more than once and the indexes never seen.
+func duplicatesAndMissing(output, pattern string, count int) ([]string, []int) {
+	seen := map[string]int{}
+	for _, name := range regexp.MustCompile(pattern).FindAllString(output, -1) {
+		seen[name]++
+	}
+
+	var duplicates []string
+	for name, times := range seen {
+		if times > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%s (%d times)", name, times))
+		}
+	}
+
+	prefix := strings.TrimSuffix(pattern, `\d{5}`)
+	var missing []int
+	for i := 0; i < count; i++ {
+		if seen[fmt.Sprintf("%s%05d", prefix, i)] == 0 {
+			missing = append(missing, i)
+		}
+	}
+
+	return duplicates, missing
+}
+
+var _ = Describe("listing thousands of resources", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		server.Handle("GET", "/v2/organizations", standin.Paginated(organizations(), perPage))
+		server.Handle("GET", "/v2/organizations/"+standin.OrgGuid+"/spaces", standin.Paginated(spaces(), perPage))
+		server.Handle("GET", "/v2/organizations/"+standin.OrgGuid+"/users", standin.Paginated(users(), perPage))
+		summary := spaceSummary()
+		server.Handle("GET", "/v2/spaces/"+standin.SpaceGuid+"/summary", func(w http.ResponseWriter, r *http.Request) {
+			standin.WriteJSON(w, http.StatusOK, summary)
+		})
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-pagination")
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	for _, l := range listings {
+		l := l
+
+		It(fmt.Sprintf("returns all %d resources exactly once from `cf %s`", l.count, strings.Join(l.args, " ")), func() {
+			before := len(server.Requests())
+
+			session := Cf(l.args...)
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			duplicates, missing := duplicatesAndMissing(string(session.Out.Contents()), l.pattern, l.count)
+			Expect(duplicates).To(BeEmpty(), "listed more than once")
+			Expect(missing).To(BeEmpty(), "missing from the output")
+
+			requests := server.Requests()[before:]
+			listRequests := 0
+			for _, request := range requests {
+				if request.Path == l.path && !strings.Contains(request.RawQuery, "q=") {
+					listRequests++
+				}
+			}
+			fmt.Fprintf(GinkgoWriter, "cf %s: %d requests, %d of them for %s (%d pages)\n", strings.Join(l.args, " "), len(requests), listRequests, l.path, l.pages)
+
+			Expect(listRequests).To(Equal(l.pages), "requests for %s", l.path)
+		})
+	}
+})
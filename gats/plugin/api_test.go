@@ -6,7 +6,7 @@ import (
 
 	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 
-	acceptanceTestHelpers "github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/cassette"
 	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
@@ -16,6 +16,8 @@ import (
 	. "github.com/onsi/gomega/gexec"
 )
 
+var pool *gatsHelpers.Pool
+
 var _ = SynchronizedBeforeSuite(func() []byte {
 	var install *Session
 	switch runtime.GOOS {
@@ -39,33 +41,38 @@ var _ = SynchronizedBeforeSuite(func() []byte {
 		}
 	}
 	Eventually(install).Should(Exit(0))
-	return nil
-}, func(_ []byte) {})
+
+	// Replayed specs never reach the foundation, so there is nothing to
+	// check and the pool's bundles only exist in the cassettes.
+	if cassette.Replaying() {
+		return gatsHelpers.NamePoolBundles(gatsHelpers.LoadConfig())
+	}
+
+	gatsHelpers.ExpectPreflight()
+	return gatsHelpers.CreatePoolBundles(gatsHelpers.LoadConfig())
+}, func(bundles []byte) {
+	pool = gatsHelpers.NewPool(gatsHelpers.LoadConfig(), bundles)
+})
 
 var _ = SynchronizedAfterSuite(func() {}, func() {
+	if !cassette.Replaying() {
+		pool.Destroy()
+	}
 	Eventually(Cf("uninstall-plugin", "GatsPlugin")).Should(Exit(0))
 })
 
 var _ = Describe("Plugin API", func() {
 
-	var (
-		config  acceptanceTestHelpers.Config
-		context *acceptanceTestHelpers.ConfiguredContext
-		env     *acceptanceTestHelpers.Environment
-	)
+	var lease *gatsHelpers.Lease
 
 	BeforeEach(func() {
-		config = acceptanceTestHelpers.LoadConfig()
-		context = acceptanceTestHelpers.NewContext(config)
-		env = acceptanceTestHelpers.NewEnvironment(context)
-
-		env.Setup()
+		lease = pool.Lease()
 
 		Expect(runtime.GOARCH).To(Equal("amd64"), "Plugin suite only runs under 64bit OS, please skip the plugin suite in 32bit OS (use flag -skipPackage='gats/plugin')")
 	})
 
 	AfterEach(func() {
-		env.Teardown()
+		lease.Release()
 	})
 
 	const (
@@ -98,12 +105,16 @@ var _ = Describe("Plugin API", func() {
 			apiResult := Cf("GetCurrentOrg").Wait(apiTimeout)
 			Expect(apiResult).To(Exit(0))
 			Expect(apiResult.Out.Contents()).To(ContainSubstring("CATS-ORG-"))
+
+			orgName := gatsHelpers.CurrentCfConfig().OrganizationFields.Name
+			Expect(orgName).NotTo(BeEmpty())
+			Expect(apiResult.Out.Contents()).To(ContainSubstring(orgName))
 		})
 	})
 
 	Describe("GetCurrentSpace()", func() {
 		It("gets the current targeted space", func() {
-			AsUser(context.AdminUserContext(), 150*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 150*time.Second, func() {
 				var cmd *Session
 
 				org := generator.RandomName()
@@ -187,6 +198,10 @@ var _ = Describe("Plugin API", func() {
 			apiResult := Cf("ApiVersion").Wait(apiTimeout)
 			Expect(apiResult).To(Exit(0))
 			Expect(len(apiResult.Out.Contents())).Should(BeNumerically(">", 21))
+
+			apiVersion := gatsHelpers.CurrentCfConfig().APIVersion
+			Expect(apiVersion).NotTo(BeEmpty())
+			Expect(apiResult.Out.Contents()).To(ContainSubstring(apiVersion))
 		})
 	})
 
@@ -216,6 +231,8 @@ var _ = Describe("Plugin API", func() {
 
 	Describe("LoggregatorEndpoint()", func() {
 		It("gets LoggregatorEndpoint", func() {
+			gatsHelpers.RequireCapabilities(gatsHelpers.Loggregator)
+
 			apiResult := Cf("LoggregatorEndpoint").Wait(apiTimeout)
 			Expect(apiResult).To(Exit(0))
 			Expect(apiResult.Out.Contents()).To(ContainSubstring("wss://loggregator"))
@@ -224,6 +241,8 @@ var _ = Describe("Plugin API", func() {
 
 	Describe("DopplerEndpoint()", func() {
 		It("gets DopplerEndpoint", func() {
+			gatsHelpers.RequireCapabilities(gatsHelpers.Doppler)
+
 			apiResult := Cf("DopplerEndpoint").Wait(apiTimeout)
 			Expect(apiResult).To(Exit(0))
 			Expect(apiResult.Out.Contents()).To(ContainSubstring("wss://doppler"))
@@ -240,9 +259,9 @@ var _ = Describe("Plugin API", func() {
 
 	Describe("GetApp() and GetApps()", func() {
 		It("gets app details and app list", func() {
-			AsUser(context.RegularUserContext(), 250*time.Second, func() {
-				space := context.RegularUserContext().Space
-				org := context.RegularUserContext().Org
+			AsUser(lease.RegularUserContext(), 250*time.Second, func() {
+				space := lease.RegularUserContext().Space
+				org := lease.RegularUserContext().Org
 
 				target := Cf("target", "-o", org, "-s", space).Wait(assertionTimeout)
 				Expect(target.ExitCode()).To(Equal(0))
@@ -277,7 +296,7 @@ var _ = Describe("Plugin API", func() {
 		It("gets the detail of a org", func() {
 			org := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 50*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 50*time.Second, func() {
 				co := Cf("create-org", org).Wait(operationTimeout)
 				Expect(co).To(Exit(0))
 
@@ -306,7 +325,7 @@ var _ = Describe("Plugin API", func() {
 			org := generator.RandomName()
 			space := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 120*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 120*time.Second, func() {
 				cmd = Cf("create-org", org).Wait(operationTimeout)
 				Expect(cmd).To(Exit(0))
 
@@ -336,7 +355,7 @@ var _ = Describe("Plugin API", func() {
 			org := generator.RandomName()
 			user := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 120*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 120*time.Second, func() {
 				cmd = Cf("create-org", org).Wait(operationTimeout)
 				Expect(cmd).To(Exit(0))
 
@@ -367,7 +386,7 @@ var _ = Describe("Plugin API", func() {
 			space := generator.RandomName()
 			user := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 150*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 150*time.Second, func() {
 				cmd = Cf("create-org", org).Wait(operationTimeout)
 				Expect(cmd).To(Exit(0))
 
@@ -412,7 +431,7 @@ var _ = Describe("Plugin API", func() {
 			org := generator.RandomName()
 			space := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 120*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 120*time.Second, func() {
 				cmd = Cf("create-org", org).Wait(operationTimeout)
 				Expect(cmd).To(Exit(0))
 
@@ -443,7 +462,7 @@ var _ = Describe("Plugin API", func() {
 			org := generator.RandomName()
 			space := generator.RandomName()
 
-			AsUser(context.AdminUserContext(), 120*time.Second, func() {
+			AsUser(lease.AdminUserContext(), 120*time.Second, func() {
 				cmd = Cf("create-org", org).Wait(operationTimeout)
 				Expect(cmd).To(Exit(0))
 
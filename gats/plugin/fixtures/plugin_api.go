@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/cli/plugin"
 )
@@ -38,10 +40,11 @@ func (c *Test1) Run(cliConnection plugin.CliConnection, args []string) {
 		result, _ := cliConnection.IsLoggedIn()
 		fmt.Println("Done IsLoggedIn:", result)
 	case "IsSSLDisabled":
-		_, err := cliConnection.IsSSLDisabled()
+		result, err := cliConnection.IsSSLDisabled()
 		if err != nil {
 			fmt.Println("Error in IsSSLDisabled()", err)
 		}
+		fmt.Println("Done IsSSLDisabled:", result)
 	case "ApiEndpoint":
 		result, _ := cliConnection.ApiEndpoint()
 		fmt.Println("Done ApiEndpoint:", result)
@@ -98,6 +101,14 @@ func (c *Test1) Run(cliConnection plugin.CliConnection, args []string) {
 	case "GetService":
 		result, _ := cliConnection.GetService(args[1])
 		fmt.Println("Done GetService:", result)
+	case "Panic":
+		panic("gats plugin panic")
+	case "PanicAfterCliCommand":
+		cliConnection.CliCommandWithoutTerminalOutput(args[1:]...)
+		panic("gats plugin panic after cf " + strings.Join(args[1:], " "))
+	case "Wait":
+		fmt.Printf("gats plugin waiting, pid %d\n", os.Getpid())
+		time.Sleep(10 * time.Minute)
 	}
 
 	// } else if args[0] == "CLI-MESSAGE-UNINSTALL" {
@@ -119,33 +130,219 @@ func (c *Test1) GetMetadata() plugin.PluginMetadata {
 			Build: 0,
 		},
 		Commands: []plugin.Command{
-			{Name: "CliCommandWithoutTerminalOutput"},
-			{Name: "CliCommand"},
-			{Name: "GetCurrentSpace"},
-			{Name: "GetCurrentOrg"},
-			{Name: "Username"},
-			{Name: "UserGuid"},
-			{Name: "UserEmail"},
-			{Name: "IsLoggedIn"},
-			{Name: "IsSSLDisabled"},
-			{Name: "ApiEndpoint"},
-			{Name: "ApiVersion"},
-			{Name: "HasAPIEndpoint"},
-			{Name: "HasOrganization"},
-			{Name: "HasSpace"},
-			{Name: "LoggregatorEndpoint"},
-			{Name: "DopplerEndpoint"},
-			{Name: "AccessToken"},
-			{Name: "GetApp"},
-			{Name: "GetApps"},
-			{Name: "GetOrg"},
-			{Name: "GetOrgs"},
-			{Name: "GetSpace"},
-			{Name: "GetSpaces"},
-			{Name: "GetOrgUsers"},
-			{Name: "GetSpaceUsers"},
-			{Name: "GetServices"},
-			{Name: "GetService"},
+			{
+				Name:     "CliCommandWithoutTerminalOutput",
+				HelpText: "Run `cf target` without terminal output and print the result",
+				UsageDetails: plugin.Usage{
+					Usage: "cf CliCommandWithoutTerminalOutput",
+				},
+			},
+			{
+				Name:     "CliCommand",
+				HelpText: "Run `cf target` and print the result",
+				UsageDetails: plugin.Usage{
+					Usage: "cf CliCommand",
+				},
+			},
+			{
+				Name:     "GetCurrentSpace",
+				HelpText: "Print the targeted space",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetCurrentSpace",
+				},
+			},
+			{
+				Name:     "GetCurrentOrg",
+				HelpText: "Print the targeted org",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetCurrentOrg",
+				},
+			},
+			{
+				Name:     "Username",
+				HelpText: "Print the logged in user's name",
+				UsageDetails: plugin.Usage{
+					Usage: "cf Username",
+				},
+			},
+			{
+				Name:     "UserGuid",
+				HelpText: "Print the logged in user's GUID",
+				UsageDetails: plugin.Usage{
+					Usage: "cf UserGuid",
+				},
+			},
+			{
+				Name:     "UserEmail",
+				HelpText: "Print the logged in user's email",
+				UsageDetails: plugin.Usage{
+					Usage: "cf UserEmail",
+				},
+			},
+			{
+				Name:     "IsLoggedIn",
+				HelpText: "Print whether a user is logged in",
+				UsageDetails: plugin.Usage{
+					Usage: "cf IsLoggedIn",
+				},
+			},
+			{
+				Name:     "IsSSLDisabled",
+				HelpText: "Print whether SSL validation is skipped",
+				UsageDetails: plugin.Usage{
+					Usage: "cf IsSSLDisabled",
+				},
+			},
+			{
+				Name:     "ApiEndpoint",
+				HelpText: "Print the targeted API endpoint",
+				UsageDetails: plugin.Usage{
+					Usage: "cf ApiEndpoint",
+				},
+			},
+			{
+				Name:     "ApiVersion",
+				HelpText: "Print the targeted API version",
+				UsageDetails: plugin.Usage{
+					Usage: "cf ApiVersion",
+				},
+			},
+			{
+				Name:     "HasAPIEndpoint",
+				HelpText: "Check whether an API endpoint is targeted",
+				UsageDetails: plugin.Usage{
+					Usage: "cf HasAPIEndpoint",
+				},
+			},
+			{
+				Name:     "HasOrganization",
+				HelpText: "Print whether an org is targeted",
+				UsageDetails: plugin.Usage{
+					Usage: "cf HasOrganization",
+				},
+			},
+			{
+				Name:     "HasSpace",
+				HelpText: "Print whether a space is targeted",
+				UsageDetails: plugin.Usage{
+					Usage: "cf HasSpace",
+				},
+			},
+			{
+				Name:     "LoggregatorEndpoint",
+				HelpText: "Print the loggregator endpoint",
+				UsageDetails: plugin.Usage{
+					Usage: "cf LoggregatorEndpoint",
+				},
+			},
+			{
+				Name:     "DopplerEndpoint",
+				HelpText: "Print the doppler endpoint",
+				UsageDetails: plugin.Usage{
+					Usage: "cf DopplerEndpoint",
+				},
+			},
+			{
+				Name:     "AccessToken",
+				HelpText: "Print a current access token",
+				UsageDetails: plugin.Usage{
+					Usage: "cf AccessToken",
+				},
+			},
+			{
+				Name:     "GetApp",
+				HelpText: "Print an app",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetApp APP_NAME",
+				},
+			},
+			{
+				Name:     "GetApps",
+				HelpText: "Print the apps in the targeted space",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetApps",
+				},
+			},
+			{
+				Name:     "GetOrg",
+				HelpText: "Print an org",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetOrg ORG_NAME",
+				},
+			},
+			{
+				Name:     "GetOrgs",
+				HelpText: "Print all orgs",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetOrgs",
+				},
+			},
+			{
+				Name:     "GetSpace",
+				HelpText: "Print a space in the targeted org",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetSpace SPACE_NAME",
+				},
+			},
+			{
+				Name:     "GetSpaces",
+				HelpText: "Print the spaces in the targeted org",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetSpaces",
+				},
+			},
+			{
+				Name:     "GetOrgUsers",
+				HelpText: "Print the users of an org",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetOrgUsers ORG_NAME [-a]",
+					Options: map[string]string{
+						"a": "List all users in the org, not only those with roles",
+					},
+				},
+			},
+			{
+				Name:     "GetSpaceUsers",
+				HelpText: "Print the users of a space",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetSpaceUsers ORG_NAME SPACE_NAME",
+				},
+			},
+			{
+				Name:     "GetServices",
+				HelpText: "Print the service instances in the targeted space",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetServices",
+				},
+			},
+			{
+				Name:     "GetService",
+				HelpText: "Print a service instance",
+				UsageDetails: plugin.Usage{
+					Usage: "cf GetService SERVICE_INSTANCE",
+				},
+			},
+			{
+				Name:     "Panic",
+				HelpText: "Panic",
+				UsageDetails: plugin.Usage{
+					Usage: "cf Panic",
+				},
+			},
+			{
+				Name:     "PanicAfterCliCommand",
+				HelpText: "Run a cf command, then panic",
+				UsageDetails: plugin.Usage{
+					Usage: "cf PanicAfterCliCommand COMMAND [ARGS...]",
+				},
+			},
+			{
+				Name:     "Wait",
+				HelpText: "Print the plugin's pid, then wait for ten minutes",
+				UsageDetails: plugin.Usage{
+					Usage: "cf Wait",
+				},
+			},
 		},
 	}
 }
@@ -1,14 +1,18 @@
 package plugin_test
 
 import (
-	. "github.com/onsi/ginkgo"
-	. "github.com/onsi/gomega"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/ab"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/cassette"
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
 
 	"testing"
 )
 
-func TestApplication(t *testing.T) {
-	RegisterFailHandler(Fail)
+var _ = cassette.RegisterHooks("plugin")
+var _ = ab.RegisterHooks("plugin")
+var _ = strict.RegisterHooks()
 
-	RunSpecs(t, "Plugin Suite")
+func TestApplication(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Plugin Suite", "plugin")
 }
@@ -0,0 +1,67 @@
+package proxy_test
+
+import (
+	"net"
+	"net/url"
+	"os"
+)
+
+var proxyVariables = []string{"http_proxy", "HTTP_PROXY", "https_proxy", "HTTPS_PROXY", "no_proxy", "NO_PROXY"}
+
+// setProxyEnvironment points the cf commands a spec runs at proxyURL, except
+// for the hosts in noProxy, and returns a func that restores the environment.
+// The upper-case variables are set as well since Go prefers them.
+func setProxyEnvironment(proxyURL, noProxy string) func() {
+	original := map[string]string{}
+	for _, name := range proxyVariables {
+		original[name] = os.Getenv(name)
+	}
+
+	for _, name := range []string{"http_proxy", "HTTP_PROXY", "https_proxy", "HTTPS_PROXY"} {
+		os.Setenv(name, proxyURL)
+	}
+	os.Setenv("no_proxy", noProxy)
+	os.Setenv("NO_PROXY", noProxy)
+
+	return func() {
+		for name, value := range original {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// hostPort is the host:port a client connects to for rawURL.
+func hostPort(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	switch u.Scheme {
+	case "http", "ws":
+		return net.JoinHostPort(u.Hostname(), "80")
+	default:
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+}
+
+// nonLoopbackIP is an IPv4 address of this machine that Go doesn't exempt
+// from proxying the way it exempts localhost, or "" if there is none.
+func nonLoopbackIP() string {
+	addresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+
+	for _, address := range addresses {
+		ipNet, ok := address.(*net.IPNet)
+		if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
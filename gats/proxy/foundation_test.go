@@ -0,0 +1,115 @@
+package proxy_test
+
+import (
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("a foundation behind the proxy", func() {
+	var (
+		config gatsHelpers.Config
+		lease  *gatsHelpers.Lease
+		proxy  *standin.Proxy
+
+		restoreEnvironment func()
+
+		appName    string
+		appTimeout time.Duration
+		pushed     bool
+	)
+
+	BeforeEach(func() {
+		if pool == nil {
+			Skip("needs a foundation: set CONFIG to a gats config")
+		}
+
+		config = gatsHelpers.LoadConfig()
+		appTimeout = config.ScaledTimeout(5 * time.Minute)
+		appName = generator.PrefixedRandomName("GATS-PROXY-")
+		pushed = false
+
+		lease = pool.Lease()
+		proxy = standin.NewProxy()
+		restoreEnvironment = setProxyEnvironment(proxy.URL(), "")
+	})
+
+	AfterEach(func() {
+		if pool == nil {
+			return
+		}
+
+		if pushed {
+			Eventually(Cf("delete", appName, "-f", "-r"), appTimeout).Should(Exit(0))
+		}
+
+		restoreEnvironment()
+		proxy.Close()
+		lease.Release()
+	})
+
+	push := func() {
+		pushed = true
+		Eventually(Cf("push", appName, "-p", gatsHelpers.NewAssets().DoraApp), appTimeout).Should(Exit(0))
+	}
+
+	It("logs in and targets through the proxy", func() {
+		args := []string{"api", gatsHelpers.CurrentCfConfig().Target}
+		if config.SkipSSLValidation {
+			args = append(args, "--skip-ssl-validation")
+		}
+		Eventually(Cf(args...), commandTimeout).Should(Exit(0))
+		Eventually(CfAuth(lease.Bundle.Username, lease.Bundle.Password), commandTimeout).Should(Exit(0))
+		Eventually(Cf("target", "-o", lease.Bundle.Org, "-s", lease.Bundle.Space), commandTimeout).Should(Exit(0))
+
+		current := gatsHelpers.CurrentCfConfig()
+		Expect(proxy.Targets()).To(ContainElement(hostPort(current.Target)))
+		Expect(proxy.Targets()).To(ContainElement(hostPort(current.UaaEndpoint)))
+	})
+
+	It("pushes an app through the proxy", func() {
+		push()
+
+		response, err := gatsHelpers.NewAppClient(config).Do(appName, gatsHelpers.AppRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(200))
+		Expect(response.Body).To(ContainSubstring("Hello from VCAP!"))
+
+		Expect(proxy.Targets()).To(ContainElement(hostPort(gatsHelpers.CurrentCfConfig().Target)))
+		for _, connection := range proxy.Connections() {
+			Expect(connection.StatusCode).To(Equal(200), "CONNECT %s", connection.Target)
+		}
+	})
+
+	It("reads recent logs through the proxy", func() {
+		gatsHelpers.RequireCapabilities(gatsHelpers.Doppler)
+		push()
+
+		Eventually(Cf("logs", appName, "--recent"), commandTimeout).Should(Exit(0))
+
+		Expect(proxy.Targets()).To(ContainElement(hostPort(gatsHelpers.CurrentCfConfig().DopplerEndPoint)))
+	})
+
+	// cf ssh dials the SSH proxy directly, so only the API and UAA requests
+	// it makes for the app and the one-time code go through the proxy.
+	It("gets what cf ssh needs through the proxy", func() {
+		gatsHelpers.RequireCapabilities(gatsHelpers.SSH)
+		push()
+
+		session := Cf("ssh", appName, "-c", "echo gats-proxy")
+		Eventually(session, appTimeout).Should(Exit(0))
+		Expect(session).To(Say("gats-proxy"))
+
+		current := gatsHelpers.CurrentCfConfig()
+		Expect(proxy.Targets()).To(ContainElement(hostPort(current.Target)))
+		Expect(proxy.Targets()).To(ContainElement(hostPort(current.UaaEndpoint)))
+	})
+})
@@ -0,0 +1,41 @@
+package proxy_test
+
+import (
+	"os"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+
+	. "github.com/onsi/ginkgo"
+
+	"testing"
+)
+
+// pool is nil when no CONFIG is given; the specs against a real foundation
+// skip themselves then, and the stand-in specs still run.
+var pool *gatsHelpers.Pool
+
+var _ = strict.RegisterHooks()
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	if os.Getenv("CONFIG") == "" {
+		return nil
+	}
+
+	gatsHelpers.ExpectPreflight()
+	return gatsHelpers.CreatePoolBundles(gatsHelpers.LoadConfig())
+}, func(bundles []byte) {
+	if len(bundles) > 0 {
+		pool = gatsHelpers.NewPool(gatsHelpers.LoadConfig(), bundles)
+	}
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	if pool != nil {
+		pool.Destroy()
+	}
+})
+
+func TestProxy(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Proxy Suite", "proxy")
+}
@@ -0,0 +1,154 @@
+package proxy_test
+
+import (
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const commandTimeout = 30 * time.Second
+
+var _ = Describe("a stand-in behind the proxy", func() {
+	// unresolvableHost can't be resolved outside the proxy (RFC 6761), so
+	// any connection that bypasses the proxy fails.
+	const unresolvableHost = "gats-standin.invalid"
+
+	var (
+		proxy  *standin.Proxy
+		server *standin.Server
+
+		restoreEnvironment func()
+		restoreCfHome      func()
+	)
+
+	login := func() {
+		Eventually(Cf("api", server.URL(), "--skip-ssl-validation"), commandTimeout).Should(Exit(0))
+		Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+		Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+		Eventually(Cf("apps"), commandTimeout).Should(Exit(0))
+	}
+
+	expectFailedAPI := func(reason string) {
+		session := Cf("api", server.URL(), "--skip-ssl-validation")
+		Eventually(session, commandTimeout).Should(Exit(1))
+		Expect(session).To(Say("Error performing request"))
+		Expect(session.Out.Contents()).To(ContainSubstring(reason))
+		Expect(server.Requests()).To(BeEmpty())
+	}
+
+	BeforeEach(func() {
+		proxy = standin.NewProxy()
+		server = standin.NewUnstarted()
+
+		_, restoreCfHome = gatsHelpers.UseTempCfHome("gats-proxy")
+		restoreEnvironment = func() {}
+	})
+
+	AfterEach(func() {
+		restoreEnvironment()
+		restoreCfHome()
+		server.Close()
+		proxy.Close()
+	})
+
+	Context("when the stand-in is only reachable through the proxy", func() {
+		BeforeEach(func() {
+			server.Host = unresolvableHost
+			proxy.Resolve(unresolvableHost, "127.0.0.1")
+			server.StartTLS(nil)
+
+			restoreEnvironment = setProxyEnvironment(proxy.URL(), "")
+		})
+
+		It("tunnels api, auth, target and apps through CONNECT", func() {
+			login()
+
+			Expect(proxy.Targets()).To(Equal([]string{hostPort(server.URL())}))
+			for _, connection := range proxy.Connections() {
+				Expect(connection.Method).To(Equal("CONNECT"))
+				Expect(connection.StatusCode).To(Equal(200))
+			}
+			Expect(server.Requests()).NotTo(BeEmpty())
+		})
+
+		Context("and the proxy requires authentication", func() {
+			BeforeEach(func() {
+				proxy.RequireAuth("gats-proxy-user", "gats-proxy-password")
+			})
+
+			It("fails without credentials", func() {
+				expectFailedAPI("Proxy Authentication Required")
+			})
+
+			It("authenticates with the credentials in https_proxy", func() {
+				restoreEnvironment()
+				restoreEnvironment = setProxyEnvironment(proxy.URLWithCredentials("gats-proxy-user", "gats-proxy-password"), "")
+
+				login()
+
+				for _, connection := range proxy.Connections() {
+					Expect(connection.Username).To(Equal("gats-proxy-user"))
+					Expect(connection.StatusCode).To(Equal(200))
+				}
+			})
+		})
+
+		Context("and the proxy refuses the connection", func() {
+			BeforeEach(func() {
+				proxy.Refuse()
+			})
+
+			It("reports what the proxy answered", func() {
+				expectFailedAPI("Forbidden")
+			})
+		})
+
+		Context("and the proxy is down", func() {
+			BeforeEach(func() {
+				proxy.Close()
+			})
+
+			It("reports that the proxy can't be reached", func() {
+				expectFailedAPI("connection refused")
+			})
+		})
+	})
+
+	Context("when the stand-in listens on a non-loopback address", func() {
+		var ip string
+
+		BeforeEach(func() {
+			ip = nonLoopbackIP()
+			if ip == "" {
+				Skip("no non-loopback IPv4 address to listen on")
+			}
+
+			Expect(server.Listen(ip + ":0")).To(Succeed())
+			server.StartTLS(nil)
+		})
+
+		It("goes through the proxy when no_proxy lists other hosts", func() {
+			restoreEnvironment = setProxyEnvironment(proxy.URL(), "gats-elsewhere.invalid")
+
+			login()
+
+			Expect(proxy.Targets()).To(Equal([]string{hostPort(server.URL())}))
+		})
+
+		It("bypasses the proxy for hosts in no_proxy", func() {
+			restoreEnvironment = setProxyEnvironment(proxy.URL(), ip)
+
+			login()
+
+			Expect(proxy.Connections()).To(BeEmpty())
+			Expect(server.Requests()).NotTo(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package pty
+
+import (
+	"bytes"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
+
+// unlockSlave grants and unlocks the slave of master and returns its path,
+// like grantpt, unlockpt and ptsname.
+func unlockSlave(master int) (string, error) {
+	err := ioctl(master, syscall.TIOCPTYGRANT, 0)
+	if err != nil {
+		return "", err
+	}
+
+	err = ioctl(master, syscall.TIOCPTYUNLK, 0)
+	if err != nil {
+		return "", err
+	}
+
+	name := make([]byte, 128)
+	err = ioctl(master, syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0])))
+	if err != nil {
+		return "", err
+	}
+	return string(name[:bytes.IndexByte(name, 0)]), nil
+}
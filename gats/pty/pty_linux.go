@@ -0,0 +1,29 @@
+package pty
+
+import (
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
+
+// unlockSlave unlocks the slave of master and returns its path, like
+// unlockpt and ptsname.
+func unlockSlave(master int) (string, error) {
+	var unlock int32
+	err := ioctl(master, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock)))
+	if err != nil {
+		return "", err
+	}
+
+	var number uint32
+	err = ioctl(master, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&number)))
+	if err != nil {
+		return "", err
+	}
+	return "/dev/pts/" + strconv.Itoa(int(number)), nil
+}
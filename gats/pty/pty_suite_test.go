@@ -0,0 +1,13 @@
+package pty_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPty(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pty Suite")
+}
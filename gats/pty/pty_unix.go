@@ -0,0 +1,81 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package pty
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// open creates a pseudo-terminal and returns the master's file descriptor,
+// non-blocking so that closing it stops a pending read, and the slave.
+func open() (int, *os.File, error) {
+	master, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	name, err := unlockSlave(master)
+	if err == nil {
+		err = syscall.SetNonblock(master, true)
+	}
+	if err != nil {
+		syscall.Close(master)
+		return -1, nil, err
+	}
+
+	slave, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		syscall.Close(master)
+		return -1, nil, err
+	}
+	return master, slave, nil
+}
+
+// controllingTerminal starts the command in a new session with its stdin as
+// the controlling terminal, so the terminal's signals and job control reach it.
+func controllingTerminal() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
+
+// disableOutputProcessing makes the terminal pass "\n" through instead of
+// turning it into "\r\n", so patterns match as they do against a
+// gexec.Session.
+func disableOutputProcessing(fd int) error {
+	attributes, err := getTermios(fd)
+	if err != nil {
+		return err
+	}
+
+	attributes.Oflag &^= syscall.ONLCR
+	return setTermios(fd, attributes)
+}
+
+// echoes reports whether the terminal echoes input.
+func echoes(fd int) (bool, error) {
+	attributes, err := getTermios(fd)
+	if err != nil {
+		return false, err
+	}
+	return attributes.Lflag&syscall.ECHO != 0, nil
+}
+
+func getTermios(fd int) (*syscall.Termios, error) {
+	attributes := &syscall.Termios{}
+	err := ioctl(fd, ioctlGetTermios, uintptr(unsafe.Pointer(attributes)))
+	return attributes, err
+}
+
+func setTermios(fd int, attributes *syscall.Termios) error {
+	return ioctl(fd, ioctlSetTermios, uintptr(unsafe.Pointer(attributes)))
+}
+
+func ioctl(fd int, request, argument uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, argument)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
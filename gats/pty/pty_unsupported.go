@@ -0,0 +1,29 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+var errUnsupported = errors.New("pseudo-terminals are not supported on " + runtime.GOOS)
+
+func open() (int, *os.File, error) {
+	return -1, nil, errUnsupported
+}
+
+func controllingTerminal() *syscall.SysProcAttr {
+	return nil
+}
+
+func disableOutputProcessing(fd int) error {
+	return errUnsupported
+}
+
+func echoes(fd int) (bool, error) {
+	return false, errUnsupported
+}
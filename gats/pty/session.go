@@ -0,0 +1,226 @@
+package pty
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+// drainTimeout is how long a session keeps reading the terminal after the
+// command exits. Children that outlive the command, such as a plugin process,
+// can hold the terminal open.
+const drainTimeout = 1 * time.Second
+
+// ctrlC is the character a terminal turns into SIGINT.
+const ctrlC = "\x03"
+
+// Session is a command whose stdin, stdout and stderr are the same
+// pseudo-terminal, so it can prompt and read answers the way it does for a
+// user. Like a gexec.Session it works with the Say and Exit matchers:
+//
+//	session := pty.Cf("delete", appName)
+//	session.Answer("Really delete the app "+appName+"?", "y", timeout)
+//	Eventually(session, timeout).Should(Exit(0))
+type Session struct {
+	Command *exec.Cmd
+
+	// Out is everything the terminal displayed: the command's output, with
+	// "\n" line endings, and the echo of what was typed.
+	Out *gbytes.Buffer
+
+	// Exited closes when the command has exited and its output was read.
+	Exited <-chan struct{}
+
+	master   *os.File
+	masterFd int
+
+	lock     sync.Mutex
+	exitCode int
+
+	// exitEcho is whether the terminal echoed when the command exited.
+	exitEcho    bool
+	exitEchoErr error
+}
+
+// Start runs cmd on a new pseudo-terminal. The terminal output also goes to
+// outWriter when it isn't nil.
+func Start(cmd *exec.Cmd, outWriter io.Writer) (*Session, error) {
+	masterFd, slave, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer slave.Close()
+
+	master := os.NewFile(uintptr(masterFd), "/dev/ptmx")
+
+	err = disableOutputProcessing(masterFd)
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	cmd.Stdin = slave
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	cmd.SysProcAttr = controllingTerminal()
+
+	err = cmd.Start()
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	exited := make(chan struct{})
+	session := &Session{
+		Command:  cmd,
+		Out:      gbytes.NewBuffer(),
+		Exited:   exited,
+		master:   master,
+		masterFd: masterFd,
+		exitCode: -1,
+	}
+
+	var output io.Writer = session.Out
+	if outWriter != nil {
+		output = io.MultiWriter(session.Out, outWriter)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		// Reading fails with EIO once no process has the terminal open.
+		io.Copy(output, master)
+		close(drained)
+	}()
+	go session.monitorForExit(drained, exited)
+
+	return session, nil
+}
+
+// Cf starts cf on a pseudo-terminal the way cf.Cf starts it on pipes: the
+// command line is reported and the transcript goes to the GinkgoWriter,
+// both redacted.
+func Cf(args ...string) *Session {
+	cmd := exec.Command("cf", args...)
+	gatsHelpers.NewRedactingReporter().Report(time.Now(), cmd)
+
+	session, err := Start(runner.CommandInterceptor(cmd), gatsHelpers.NewRedactingWriter(ginkgo.GinkgoWriter))
+	Expect(err).NotTo(HaveOccurred())
+	return session
+}
+
+func (s *Session) monitorForExit(drained <-chan struct{}, exited chan<- struct{}) {
+	s.Command.Wait()
+
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+	}
+
+	s.lock.Lock()
+	s.exitEcho, s.exitEchoErr = echoes(s.masterFd)
+	s.master.Close()
+	s.Out.Close()
+	status := s.Command.ProcessState.Sys().(syscall.WaitStatus)
+	if status.Signaled() {
+		s.exitCode = 128 + int(status.Signal())
+	} else {
+		s.exitCode = status.ExitStatus()
+	}
+	s.lock.Unlock()
+
+	close(exited)
+}
+
+// Buffer makes Session a gbytes.BufferProvider.
+func (s *Session) Buffer() *gbytes.Buffer {
+	return s.Out
+}
+
+// ExitCode is -1 while the command runs, 128+n when it was killed by signal
+// n, and its exit status otherwise.
+func (s *Session) ExitCode() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.exitCode
+}
+
+// Transcript is everything the terminal displayed so far.
+func (s *Session) Transcript() string {
+	return string(s.Out.Contents())
+}
+
+// Type sends text to the command as if it was typed, without a return.
+func (s *Session) Type(text string) error {
+	_, err := s.master.Write([]byte(text))
+	return err
+}
+
+// PressCtrlC types the interrupt character, which makes the terminal send
+// SIGINT to the command and every process in its group, such as a plugin.
+func (s *Session) PressCtrlC() error {
+	return s.Type(ctrlC)
+}
+
+// SendLine types line followed by return.
+func (s *Session) SendLine(line string) error {
+	return s.Type(line + "\r")
+}
+
+// Answer waits up to timeout for the command to prompt with prompt and
+// answers it. The prompt is matched up to the "> " the CLI ends it with, and
+// only in output that the Say matcher hasn't consumed yet.
+func (s *Session) Answer(prompt, answer string, timeout time.Duration) {
+	EventuallyWithOffset(1, s, timeout).Should(gbytes.Say("%s> ", regexp.QuoteMeta(prompt)), "no %q prompt", prompt)
+	ExpectWithOffset(1, s.SendLine(answer)).To(Succeed())
+}
+
+// Echo reports whether the terminal echoes typed text, or whether it did
+// when the command exited. A command that turns echo off for a password
+// has to turn it back on, even when it is interrupted.
+func (s *Session) Echo() (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.exitCode != -1 {
+		return s.exitEcho, s.exitEchoErr
+	}
+	return echoes(s.masterFd)
+}
+
+// Wait waits for the command to exit and returns the session.
+func (s *Session) Wait(timeout ...interface{}) *Session {
+	EventuallyWithOffset(1, s, timeout...).Should(gexec.Exit())
+	return s
+}
+
+// Signal sends signal to the command, unless it has exited.
+func (s *Session) Signal(signal os.Signal) *Session {
+	if s.ExitCode() != -1 {
+		return s
+	}
+	s.Command.Process.Signal(signal)
+	return s
+}
+
+func (s *Session) Interrupt() *Session {
+	return s.Signal(os.Interrupt)
+}
+
+func (s *Session) Terminate() *Session {
+	return s.Signal(syscall.SIGTERM)
+}
+
+func (s *Session) Kill() *Session {
+	return s.Signal(os.Kill)
+}
@@ -0,0 +1,108 @@
+package pty_test
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const timeout = 5 * time.Second
+
+func start(script string) *pty.Session {
+	session, err := pty.Start(exec.Command("sh", "-c", script), nil)
+	Expect(err).NotTo(HaveOccurred())
+	return session
+}
+
+var _ = Describe("Session", func() {
+	BeforeEach(func() {
+		if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+			Skip("pseudo-terminals are not supported on " + runtime.GOOS)
+		}
+	})
+
+	It("runs the command on a terminal", func() {
+		session := start(`test -t 0 && test -t 1 && test -t 2 && echo on a terminal`)
+
+		Eventually(session, timeout).Should(Exit(0))
+		Expect(session).To(Say("on a terminal"))
+	})
+
+	It("keeps \\n line endings", func() {
+		session := start(`printf 'one\ntwo\n'`)
+
+		Eventually(session, timeout).Should(Exit(0))
+		Expect(session.Transcript()).To(Equal("one\ntwo\n"))
+	})
+
+	It("answers prompts", func() {
+		session := start(`printf "\nName> "; read name; printf "\nColour> "; read colour; echo "$name likes $colour"`)
+
+		session.Answer("Name", "gats", timeout)
+		session.Answer("Colour", "green", timeout)
+
+		Eventually(session, timeout).Should(Exit(0))
+		Expect(session).To(Say("gats likes green"))
+	})
+
+	It("shows what was typed only while the terminal echoes", func() {
+		session := start(`stty -echo; printf "\nSecret> "; read secret; stty echo; echo; echo "read $secret"`)
+
+		session.Answer("Secret", "hidden", timeout)
+
+		Eventually(session, timeout).Should(Exit(0))
+		Expect(strings.Count(session.Transcript(), "hidden")).To(Equal(1))
+		Expect(session).To(Say("read hidden"))
+	})
+
+	It("fails when the prompt doesn't come in time", func() {
+		session := start(`sleep 5`)
+		defer session.Kill()
+
+		failures := InterceptGomegaFailures(func() {
+			session.Answer("Name", "gats", 100*time.Millisecond)
+		})
+		Expect(failures).To(HaveLen(1))
+		Expect(failures[0]).To(ContainSubstring(`no "Name" prompt`))
+	})
+
+	It("reports the exit status", func() {
+		session := start(`exit 3`)
+
+		Eventually(session, timeout).Should(Exit(3))
+	})
+
+	It("reports 128 plus the signal for a command killed by one", func() {
+		session := start(`printf "\nWaiting> "; read answer`)
+		Eventually(session, timeout).Should(Say("Waiting> "))
+
+		session.Kill()
+		Eventually(session, timeout).Should(Exit(137))
+	})
+
+	It("interrupts the command with Ctrl-C", func() {
+		session := start(`printf "\nWaiting> "; sleep 30`)
+		Eventually(session, timeout).Should(Say("Waiting> "))
+
+		Expect(session.PressCtrlC()).To(Succeed())
+		Eventually(session, timeout).Should(Exit(130))
+	})
+
+	It("reports whether the terminal echoes, also once the command exited", func() {
+		session := start(`printf "\nWaiting> "; read answer; stty -echo`)
+		Eventually(session, timeout).Should(Say("Waiting> "))
+		Expect(session.Echo()).To(BeTrue())
+
+		Expect(session.SendLine("")).To(Succeed())
+		Eventually(session, timeout).Should(Exit(0))
+		Expect(session.Echo()).To(BeFalse())
+	})
+})
@@ -0,0 +1,79 @@
+package signals_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const commandTimeout = 1 * time.Minute
+
+// signals are the ways cf gets stopped from outside: Ctrl-C, and kill or a
+// CI job timing out.
+var signals = []syscall.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+var signalNames = map[syscall.Signal]string{
+	syscall.SIGINT:  "SIGINT",
+	syscall.SIGTERM: "SIGTERM",
+}
+
+var _ = BeforeEach(func() {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		Skip("needs POSIX signals and a pseudo-terminal, which " + runtime.GOOS + " doesn't have")
+	}
+})
+
+// killedBy is the exit code of a command that didn't handle signal, the way
+// gexec and shells report it.
+func killedBy(signal syscall.Signal) int {
+	return 128 + int(signal)
+}
+
+// setEnvironment sets the variables in values and returns a func that
+// restores them.
+func setEnvironment(values map[string]string) func() {
+	original := map[string]string{}
+	for name, value := range values {
+		original[name] = os.Getenv(name)
+		os.Setenv(name, value)
+	}
+
+	return func() {
+		for name, value := range original {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// leftovers lists what is left in dir.
+func leftovers(dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	Expect(err).NotTo(HaveOccurred())
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// running reports whether the process pid is still alive. A zombie doesn't
+// count, since nothing is left of it but its exit status.
+func running(pid int) bool {
+	output, err := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		// ps exits 1 when there is no such process.
+		return false
+	}
+
+	state := strings.TrimSpace(string(output))
+	return state != "" && !strings.HasPrefix(state, "Z")
+}
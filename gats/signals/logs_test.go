@@ -0,0 +1,58 @@
+package signals_test
+
+import (
+	"fmt"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("log streaming interrupted while it tails", func() {
+	var (
+		lease *gatsHelpers.Lease
+
+		appName    string
+		appTimeout time.Duration
+	)
+
+	BeforeEach(func() {
+		if pool == nil {
+			Skip("needs a foundation: set CONFIG to a gats config")
+		}
+
+		config := gatsHelpers.LoadConfig()
+		appTimeout = config.ScaledTimeout(5 * time.Minute)
+		appName = generator.PrefixedRandomName("GATS-SIGNALS-")
+
+		lease = pool.Lease()
+		Eventually(Cf("push", appName, "-p", gatsHelpers.NewAssets().DoraApp), appTimeout).Should(Exit(0))
+	})
+
+	AfterEach(func() {
+		if pool == nil {
+			return
+		}
+
+		Eventually(Cf("delete", appName, "-f", "-r"), commandTimeout).Should(Exit(0))
+		lease.Release()
+	})
+
+	for _, signal := range signals {
+		signal := signal
+
+		It(fmt.Sprintf("exits %d on %s", killedBy(signal), signalNames[signal]), func() {
+			session := Cf("logs", appName)
+			Eventually(session, commandTimeout).Should(Say("Connected, tailing logs for app"))
+
+			session.Signal(signal)
+			Eventually(session, commandTimeout).Should(Exit(killedBy(signal)))
+		})
+	}
+})
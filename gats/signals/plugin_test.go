@@ -0,0 +1,121 @@
+package signals_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// pluginExitTimeout is how long a plugin may outlive cf before it counts as
+// orphaned.
+const pluginExitTimeout = 5 * time.Second
+
+const waitingPluginPattern = `gats plugin waiting, pid (\d+)`
+
+var waitingPlugin = regexp.MustCompile(waitingPluginPattern)
+
+// pluginPidIn returns the pid the plugin reported in output, or 0 if it
+// hasn't yet.
+func pluginPidIn(output []byte) int {
+	match := waitingPlugin.FindSubmatch(output)
+	if match == nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(string(match[1]))
+	Expect(err).NotTo(HaveOccurred())
+	return pid
+}
+
+var _ = Describe("a plugin command interrupted while it runs", func() {
+	var (
+		output BufferProvider
+		stopCf func()
+
+		restoreCfHome      func()
+		restoreEnvironment func()
+	)
+
+	BeforeEach(func() {
+		output = nil
+		stopCf = func() {}
+
+		_, restoreCfHome = gatsHelpers.UseTempCfHome("gats-signals")
+		restoreEnvironment = setEnvironment(map[string]string{"CF_COLOR": "false"})
+
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+	})
+
+	AfterEach(func() {
+		stopCf()
+
+		// cf doesn't stop the plugin when it is killed, and an orphaned
+		// plugin would otherwise wait out its ten minutes.
+		if output != nil {
+			if pid := pluginPidIn(output.Buffer().Contents()); pid != 0 && running(pid) {
+				if process, err := os.FindProcess(pid); err == nil {
+					process.Kill()
+				}
+			}
+		}
+
+		restoreEnvironment()
+		restoreCfHome()
+	})
+
+	// waitForPlugin waits until the plugin reports its pid in output.
+	waitForPlugin := func() int {
+		Eventually(output, commandTimeout).Should(Say(waitingPluginPattern))
+		return pluginPidIn(output.Buffer().Contents())
+	}
+
+	expectPluginGone := func(pid int) {
+		Eventually(func() bool { return running(pid) }, pluginExitTimeout).Should(BeFalse(), "the plugin, pid %d, outlived cf", pid)
+	}
+
+	// Ctrl-C reaches the plugin too, since it shares cf's process group and
+	// terminal.
+	It("stops cf and the plugin on Ctrl-C", func() {
+		session := pty.Cf("Wait")
+		output, stopCf = session, func() { session.Kill().Wait() }
+		pluginPid := waitForPlugin()
+
+		Expect(session.PressCtrlC()).To(Succeed())
+		Eventually(session, commandTimeout).Should(Exit())
+		Expect(session.ExitCode()).NotTo(Equal(0))
+
+		expectPluginGone(pluginPid)
+	})
+
+	// A signal sent to cf alone, as kill or a supervisor sends it, only
+	// reaches the plugin if cf passes it on.
+	for _, signal := range signals {
+		signal := signal
+
+		// cf 6 fails this: it has no handler for SIGINT or SIGTERM outside
+		// the password prompt, so the signal kills it before the deferred
+		// stopPlugin in the CLI's plugin/rpc/run_plugin.go runs, and the
+		// plugin keeps running.
+		It(fmt.Sprintf("exits %d on %s and leaves no plugin process behind", killedBy(signal), signalNames[signal]), func() {
+			session := Cf("Wait")
+			output, stopCf = session, func() { session.Kill().Wait() }
+			pluginPid := waitForPlugin()
+
+			session.Signal(signal)
+			Eventually(session, commandTimeout).Should(Exit(killedBy(signal)))
+
+			expectPluginGone(pluginPid)
+		})
+	}
+})
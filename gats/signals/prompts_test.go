@@ -0,0 +1,114 @@
+package signals_test
+
+import (
+	"fmt"
+	"os"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/pty"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// passwordInterruptedExitCode is what cf exits with when a signal arrives at
+// a password prompt, after turning the terminal's echo back on.
+const passwordInterruptedExitCode = 2
+
+var _ = Describe("a prompt interrupted while it waits", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome      func()
+		restoreEnvironment func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-signals")
+		restoreEnvironment = setEnvironment(map[string]string{"CF_COLOR": "false"})
+	})
+
+	AfterEach(func() {
+		restoreEnvironment()
+		restoreCfHome()
+		server.Close()
+	})
+
+	Context("at the password prompt", func() {
+		var session *pty.Session
+
+		BeforeEach(func() {
+			session = pty.Cf("login", "-a", server.URL(), "-u", standin.Username)
+			Eventually(session, commandTimeout).Should(Say("Password> "))
+
+			// cf turns echo off in a child process after showing the prompt.
+			Eventually(session.Echo, commandTimeout).Should(BeFalse())
+		})
+
+		expectEchoRestored := func() {
+			Eventually(session, commandTimeout).Should(Exit(passwordInterruptedExitCode))
+			Expect(session.Echo()).To(BeTrue(), "cf left the terminal without echo")
+
+			config, err := gatsHelpers.ReadCfConfig(cfHome)
+			if !os.IsNotExist(err) {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config.AccessToken).To(BeEmpty())
+			}
+		}
+
+		It("restores echo and exits 2 on Ctrl-C", func() {
+			Expect(session.PressCtrlC()).To(Succeed())
+			expectEchoRestored()
+		})
+
+		for _, signal := range signals {
+			signal := signal
+
+			It(fmt.Sprintf("restores echo and exits 2 on %s", signalNames[signal]), func() {
+				session.Signal(signal)
+				expectEchoRestored()
+			})
+		}
+	})
+
+	Context("at a confirmation", func() {
+		var session *pty.Session
+
+		BeforeEach(func() {
+			Expect(server.SeedCfHome(cfHome)).To(Succeed())
+			serveApp(server)
+
+			session = pty.Cf("delete", appName)
+			Eventually(session, commandTimeout).Should(Say("Really delete the app " + appName + `\?> `))
+		})
+
+		expectNothingDeleted := func() {
+			Expect(session.Echo()).To(BeTrue())
+			for _, request := range server.Requests() {
+				Expect(request.Method).NotTo(Equal("DELETE"), "cf deleted %s without a yes", request.Path)
+			}
+		}
+
+		It("exits 130 without deleting on Ctrl-C", func() {
+			Expect(session.PressCtrlC()).To(Succeed())
+			Eventually(session, commandTimeout).Should(Exit(killedBy(signals[0])))
+			expectNothingDeleted()
+		})
+
+		for _, signal := range signals {
+			signal := signal
+
+			It(fmt.Sprintf("exits %d without deleting on %s", killedBy(signal), signalNames[signal]), func() {
+				session.Signal(signal)
+				Eventually(session, commandTimeout).Should(Exit(killedBy(signal)))
+				expectNothingDeleted()
+			})
+		}
+	})
+})
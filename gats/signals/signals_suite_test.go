@@ -0,0 +1,61 @@
+package signals_test
+
+import (
+	"encoding/json"
+	"os"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+var (
+	pluginPath string
+
+	// pool is nil when no CONFIG is given; the specs against a real
+	// foundation skip themselves then, and the stand-in specs still run.
+	pool *gatsHelpers.Pool
+)
+
+type suiteSetup struct {
+	PluginPath string `json:"plugin_path"`
+	Bundles    []byte `json:"bundles"`
+}
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	path, err := Build("code.cloudfoundry.org/cli-acceptance-tests/gats/plugin/fixtures")
+	Expect(err).NotTo(HaveOccurred())
+
+	setup := suiteSetup{PluginPath: path}
+	if os.Getenv("CONFIG") != "" {
+		gatsHelpers.ExpectPreflight()
+		setup.Bundles = gatsHelpers.CreatePoolBundles(gatsHelpers.LoadConfig())
+	}
+
+	encoded, err := json.Marshal(setup)
+	Expect(err).NotTo(HaveOccurred())
+	return encoded
+}, func(encoded []byte) {
+	var setup suiteSetup
+	Expect(json.Unmarshal(encoded, &setup)).To(Succeed())
+
+	pluginPath = setup.PluginPath
+	if len(setup.Bundles) > 0 {
+		pool = gatsHelpers.NewPool(gatsHelpers.LoadConfig(), setup.Bundles)
+	}
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	if pool != nil {
+		pool.Destroy()
+	}
+	CleanupBuildArtifacts()
+})
+
+func TestSignals(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Signals Suite", "signals")
+}
@@ -0,0 +1,154 @@
+package signals_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	appName = "gats-app"
+	appGuid = "00000000-0000-0000-0000-00000000a005"
+
+	// appSize and uploadRate keep the throttled upload going for about
+	// 20 seconds, long enough to interrupt it.
+	appSize    = 2 * 1024 * 1024
+	uploadRate = 100 * 1024
+)
+
+var bitsPath = "/v2/apps/" + appGuid + "/bits"
+
+// serveApp makes the stand-in serve what `cf push appName --no-route
+// --no-start` needs: the existing, stopped app, its update, resource
+// matching and the upload of its bits.
+func serveApp(server *standin.Server) {
+	app := standin.Resource(appGuid, map[string]interface{}{
+		"name":       appName,
+		"space_guid": standin.SpaceGuid,
+		"state":      "STOPPED",
+		"instances":  1,
+		"memory":     256,
+		"disk_quota": 1024,
+	})
+
+	server.Handle("GET", "/v2/spaces/"+standin.SpaceGuid+"/apps", standin.Paginated([]interface{}{app}, 50))
+	server.Handle("PUT", "/v2/apps/"+appGuid, func(w http.ResponseWriter, r *http.Request) {
+		standin.WriteJSON(w, http.StatusCreated, app)
+	})
+	server.Handle("PUT", "/v2/resource_match", func(w http.ResponseWriter, r *http.Request) {
+		standin.WriteJSON(w, http.StatusOK, []interface{}{})
+	})
+	server.Handle("PUT", bitsPath, func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+// writeApp fills dir with an app whose random content doesn't compress.
+func writeApp(dir string) {
+	Expect(ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>gats</html>"), 0644)).To(Succeed())
+
+	content := make([]byte, appSize)
+	_, err := rand.Read(content)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(ioutil.WriteFile(filepath.Join(dir, "content.bin"), content, 0644)).To(Succeed())
+}
+
+var _ = Describe("a push interrupted during the upload", func() {
+	var (
+		server *standin.Server
+		cfHome string
+		appDir string
+		tmpDir string
+
+		session *Session
+
+		restoreCfHome      func()
+		restoreEnvironment func()
+	)
+
+	BeforeEach(func() {
+		session = nil
+
+		server = standin.New()
+		serveApp(server)
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-signals")
+
+		var err error
+		appDir, err = ioutil.TempDir("", "gats-signals-app")
+		Expect(err).NotTo(HaveOccurred())
+		writeApp(appDir)
+
+		// cf creates the upload's directory, zip file and request body in
+		// TMPDIR, so an empty one shows whether it cleaned up.
+		tmpDir, err = ioutil.TempDir("", "gats-signals-tmp")
+		Expect(err).NotTo(HaveOccurred())
+
+		restoreEnvironment = setEnvironment(map[string]string{"TMPDIR": tmpDir})
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		// A failed spec can leave the push running, and a killed cf leaves
+		// the upload's temporary files in tmpDir.
+		if session != nil {
+			session.Kill().Wait()
+		}
+
+		restoreEnvironment()
+		os.RemoveAll(tmpDir)
+		os.RemoveAll(appDir)
+		restoreCfHome()
+		server.Close()
+	})
+
+	push := func() *Session {
+		session = Cf("push", appName, "-p", appDir, "--no-route", "--no-start", "--no-manifest")
+		return session
+	}
+
+	// interrupt sends signal to a push once its throttled upload is running.
+	interrupt := func(signal syscall.Signal) {
+		server.Inject("PUT", bitsPath, standin.ThrottleFault(uploadRate))
+
+		push()
+		Eventually(func() int { return server.InFlight("PUT", bitsPath) }, commandTimeout).Should(Equal(1))
+		Expect(leftovers(tmpDir)).NotTo(BeEmpty(), "the upload has no temporary files to clean up")
+
+		session.Signal(signal)
+		Eventually(session, commandTimeout).Should(Exit(killedBy(signal)))
+	}
+
+	It("leaves no temporary files when it isn't interrupted", func() {
+		Eventually(push(), commandTimeout).Should(Exit(0))
+
+		Expect(leftovers(tmpDir)).To(BeEmpty())
+	})
+
+	for _, signal := range signals {
+		signal := signal
+
+		// cf 6 fails this: it has no handler for SIGINT or SIGTERM outside
+		// the password prompt, so the signal kills it before the deferred
+		// removal of the "apps" directory and "uploads" zip file in the
+		// CLI's cf/commands/application/push.go runs.
+		It(fmt.Sprintf("exits %d on %s and removes the upload's temporary files", killedBy(signal), signalNames[signal]), func() {
+			interrupt(signal)
+
+			Expect(leftovers(tmpDir)).To(BeEmpty(), "cf left temporary files from the upload in TMPDIR")
+		})
+	}
+})
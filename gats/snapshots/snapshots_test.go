@@ -0,0 +1,134 @@
+package snapshots_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/golden"
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/generator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 1 * time.Minute
+
+	// securityGroupRules is the fixed rule set of the snapshotted security
+	// group.
+	securityGroupRules = `[{"protocol":"tcp","destination":"10.0.11.0/24","ports":"80,443","description":"gats snapshot"}]`
+)
+
+var goldenDir = filepath.Join("..", "testdata", "output")
+
+var _ = Describe("human output", func() {
+	var (
+		config     gatsHelpers.Config
+		lease      *gatsHelpers.Lease
+		normalizer *golden.Normalizer
+		failures   *golden.Failures
+
+		appTimeout time.Duration
+	)
+
+	BeforeEach(func() {
+		lease = nil
+		config = gatsHelpers.LoadConfig()
+		appTimeout = config.ScaledTimeout(5 * time.Minute)
+		lease = pool.Lease()
+
+		current := gatsHelpers.CurrentCfConfig()
+
+		normalizer = golden.NewNormalizer()
+		normalizer.Name(lease.Bundle.Org, "{{org}}")
+		normalizer.Name(lease.Bundle.Space, "{{space}}")
+		normalizer.Name(lease.Bundle.Quota, "{{quota}}")
+		normalizer.Name(lease.Bundle.Username, "{{user}}")
+		normalizer.Name(current.Target, "{{api}}")
+		normalizer.Name(current.APIVersion, "{{api-version}}")
+		normalizer.Name(config.AppsDomain, "{{apps-domain}}")
+		normalizer.Name(config.AdminUser, "{{admin}}")
+
+		failures = &golden.Failures{}
+	})
+
+	AfterEach(func() {
+		if lease != nil {
+			lease.Release()
+		}
+	})
+
+	// snapshot checks the normalized stdout of a successful cf command
+	// against goldenDir/<name>.txt.
+	snapshot := func(name string, session *Session) {
+		Eventually(session, commandTimeout).Should(Exit(0))
+		failures.Check(filepath.Join(goldenDir, name+".txt"), normalizer.Normalize(string(session.Out.Contents())))
+	}
+
+	expectNoFailures := func() {
+		if failures.Len() > 0 {
+			Fail(fmt.Sprintf("%d outputs differ from %s:\n\n%s", failures.Len(), goldenDir, failures))
+		}
+	}
+
+	It("matches the golden output of the org, space and foundation commands", func() {
+		snapshot("target", Cf("target"))
+		snapshot("org", Cf("org", lease.Bundle.Org))
+		snapshot("space", Cf("space", lease.Bundle.Space))
+		snapshot("marketplace", Cf("marketplace"))
+
+		// `cf quotas` lists the quota of every bundle in the pool, so only
+		// the leased one is snapshotted.
+		AsUser(lease.AdminUserContext(), commandTimeout, func() {
+			snapshot("quota", Cf("quota", lease.Bundle.Quota))
+		})
+
+		rulesDir, err := ioutil.TempDir("", "gats-snapshots")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(rulesDir)
+
+		rules := filepath.Join(rulesDir, "rules.json")
+		Expect(ioutil.WriteFile(rules, []byte(securityGroupRules), 0644)).To(Succeed())
+
+		securityGroup := generator.PrefixedRandomName("GATS-SNAPSHOT-")
+		normalizer.Name(securityGroup, "{{security-group}}")
+
+		AsUser(lease.AdminUserContext(), commandTimeout, func() {
+			Eventually(Cf("create-security-group", securityGroup, rules), commandTimeout).Should(Exit(0))
+			defer func() {
+				Eventually(Cf("delete-security-group", securityGroup, "-f"), commandTimeout).Should(Exit(0))
+			}()
+
+			snapshot("security-group", Cf("security-group", securityGroup))
+		})
+
+		expectNoFailures()
+	})
+
+	It("matches the golden output of the app and service commands", func() {
+		appName := generator.PrefixedRandomName("GATS-SNAPSHOT-")
+		serviceName := generator.PrefixedRandomName("GATS-SNAPSHOT-")
+
+		// The app's route uses its name lower-cased as the host.
+		normalizer.Name(appName, "{{app}}")
+		normalizer.Name(strings.ToLower(appName), "{{app}}")
+		normalizer.Name(serviceName, "{{service}}")
+
+		Eventually(Cf("push", appName, "-p", gatsHelpers.NewAssets().DoraApp, "-m", "256M", "-k", "512M", "-i", "1"), appTimeout).Should(Exit(0))
+		Eventually(Cf("create-user-provided-service", serviceName, "-p", `{"uri":"gats://snapshot"}`), commandTimeout).Should(Exit(0))
+		Eventually(Cf("bind-service", appName, serviceName), commandTimeout).Should(Exit(0))
+
+		snapshot("apps", Cf("apps"))
+		snapshot("app", Cf("app", appName))
+		snapshot("services", Cf("services"))
+
+		expectNoFailures()
+	})
+})
@@ -0,0 +1,209 @@
+package ssl_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 30 * time.Second
+
+	invalidCertTip = "TIP: Use 'cf api --skip-ssl-validation' to continue with an insecure API endpoint"
+)
+
+// endpoint is a way a stand-in's certificate can fail validation.
+type endpoint struct {
+	description string
+
+	// trustCA puts the suite's CA in SSL_CERT_FILE, so the certificate fails
+	// for its own reason rather than for being signed by an unknown CA.
+	trustCA bool
+
+	certificate func(ca *standin.CertificateAuthority) (tls.Certificate, error)
+}
+
+var invalidEndpoints = []endpoint{
+	{
+		description: "a self-signed certificate",
+		certificate: func(*standin.CertificateAuthority) (tls.Certificate, error) {
+			return standin.SelfSigned([]string{"127.0.0.1"})
+		},
+	},
+	{
+		description: "a certificate from an untrusted CA",
+		certificate: func(ca *standin.CertificateAuthority) (tls.Certificate, error) {
+			return ca.Issue([]string{"127.0.0.1"}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		},
+	},
+	{
+		description: "an expired certificate",
+		trustCA:     true,
+		certificate: func(ca *standin.CertificateAuthority) (tls.Certificate, error) {
+			return ca.Issue([]string{"127.0.0.1"}, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+		},
+	},
+	{
+		description: "a certificate for the wrong hostname",
+		trustCA:     true,
+		certificate: func(ca *standin.CertificateAuthority) (tls.Certificate, error) {
+			return ca.Issue([]string{"gats-wrong-host.example.com"}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		},
+	},
+}
+
+var _ = Describe("SSL validation", func() {
+	var (
+		server *standin.Server
+		ca     *standin.CertificateAuthority
+		cfHome string
+
+		restoreCfHome       func()
+		originalSSLCertFile string
+		hadOriginalCertFile bool
+	)
+
+	// trustCA makes the CLI trust ca instead of the system roots. Only
+	// platforms whose Go crypto/x509 reads SSL_CERT_FILE honour it.
+	trustCA := func() {
+		if runtime.GOOS != "linux" {
+			Skip("SSL_CERT_FILE only replaces the system roots on Linux")
+		}
+
+		path := filepath.Join(cfHome, "gats-ca.pem")
+		Expect(ioutil.WriteFile(path, ca.PEM(), 0600)).To(Succeed())
+		os.Setenv("SSL_CERT_FILE", path)
+	}
+
+	serve := func(certificate tls.Certificate, err error) {
+		Expect(err).NotTo(HaveOccurred())
+		server = standin.NewUnstarted()
+		server.StartTLS(standin.TLSConfig(certificate))
+	}
+
+	pluginSSLDisabled := func() *Session {
+		session := Cf("IsSSLDisabled")
+		Eventually(session, commandTimeout).Should(Exit(0))
+		Expect(session.Out.Contents()).NotTo(ContainSubstring("Error"))
+		return session
+	}
+
+	BeforeEach(func() {
+		var err error
+		ca, err = standin.NewCertificateAuthority("gats-ssl-ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-ssl")
+		originalSSLCertFile, hadOriginalCertFile = os.LookupEnv("SSL_CERT_FILE")
+
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			server.Close()
+			server = nil
+		}
+
+		// An empty SSL_CERT_FILE is not the same as an unset one to every
+		// TLS stack, so don't leave it behind.
+		if hadOriginalCertFile {
+			os.Setenv("SSL_CERT_FILE", originalSSLCertFile)
+		} else {
+			os.Unsetenv("SSL_CERT_FILE")
+		}
+		restoreCfHome()
+	})
+
+	for _, e := range invalidEndpoints {
+		e := e
+
+		Context("when the API endpoint serves "+e.description, func() {
+			BeforeEach(func() {
+				if e.trustCA {
+					trustCA()
+				}
+				serve(e.certificate(ca))
+			})
+
+			It("refuses to target it", func() {
+				session := Cf("api", server.URL())
+				Eventually(session, commandTimeout).Should(Exit(1))
+				Expect(session).To(Say("Invalid SSL Cert for"))
+				Expect(session).To(Say(invalidCertTip))
+
+				data := gatsHelpers.CurrentCfConfig()
+				Expect(data.Target).To(BeEmpty())
+				Expect(data.SSLDisabled).To(BeFalse())
+			})
+
+			Context("with --skip-ssl-validation", func() {
+				It("targets it and persists SSL validation as disabled", func() {
+					Eventually(Cf("api", server.URL(), "--skip-ssl-validation"), commandTimeout).Should(Exit(0))
+
+					data := gatsHelpers.CurrentCfConfig()
+					Expect(data.Target).To(Equal(server.URL()))
+					Expect(data.SSLDisabled).To(BeTrue())
+
+					Expect(pluginSSLDisabled()).To(Say("Done IsSSLDisabled: true"))
+				})
+
+				It("skips validation on later commands too", func() {
+					Eventually(Cf("api", server.URL(), "--skip-ssl-validation"), commandTimeout).Should(Exit(0))
+
+					Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+					Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+					Eventually(Cf("apps"), commandTimeout).Should(Exit(0))
+				})
+
+				It("validates again once the endpoint is set without it", func() {
+					Eventually(Cf("api", server.URL(), "--skip-ssl-validation"), commandTimeout).Should(Exit(0))
+
+					session := Cf("api", server.URL())
+					Eventually(session, commandTimeout).Should(Exit(1))
+					Expect(session).To(Say("Invalid SSL Cert for"))
+
+					data := gatsHelpers.CurrentCfConfig()
+					Expect(data.Target).To(BeEmpty())
+					Expect(data.SSLDisabled).To(BeFalse())
+				})
+			})
+		})
+	}
+
+	Context("when SSL_CERT_FILE trusts the CA that signed the endpoint's certificate", func() {
+		BeforeEach(func() {
+			trustCA()
+			serve(ca.Issue([]string{"127.0.0.1"}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+		})
+
+		It("targets it with SSL validation enabled", func() {
+			Eventually(Cf("api", server.URL()), commandTimeout).Should(Exit(0))
+
+			data := gatsHelpers.CurrentCfConfig()
+			Expect(data.Target).To(Equal(server.URL()))
+			Expect(data.SSLDisabled).To(BeFalse())
+
+			Expect(pluginSSLDisabled()).To(Say("Done IsSSLDisabled: false"))
+		})
+
+		It("logs in and runs commands against it", func() {
+			Eventually(Cf("api", server.URL()), commandTimeout).Should(Exit(0))
+			Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+			Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+			Eventually(Cf("apps"), commandTimeout).Should(Exit(0))
+		})
+	})
+})
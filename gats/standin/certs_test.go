@@ -0,0 +1,73 @@
+package standin_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateAuthority", func() {
+	var (
+		ca    *CertificateAuthority
+		roots *x509.CertPool
+	)
+
+	get := func(server *Server) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+		response, err := client.Get(server.URL() + "/v2/info")
+		if err == nil {
+			response.Body.Close()
+		}
+		return err
+	}
+
+	serve := func(certificate tls.Certificate, err error) *Server {
+		Expect(err).NotTo(HaveOccurred())
+		server := NewUnstarted()
+		server.StartTLS(TLSConfig(certificate))
+		return server
+	}
+
+	BeforeEach(func() {
+		var err error
+		ca, err = NewCertificateAuthority("gats-test-ca")
+		Expect(err).NotTo(HaveOccurred())
+
+		roots = x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(ca.PEM())).To(BeTrue())
+	})
+
+	It("issues certificates its PEM makes trusted", func() {
+		server := serve(ca.Issue([]string{"127.0.0.1"}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+		defer server.Close()
+
+		Expect(get(server)).To(Succeed())
+	})
+
+	It("issues certificates for the wrong host", func() {
+		server := serve(ca.Issue([]string{"gats-wrong-host.example.com"}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+		defer server.Close()
+
+		Expect(get(server)).To(MatchError(ContainSubstring("127.0.0.1")))
+	})
+
+	It("issues expired certificates", func() {
+		server := serve(ca.Issue([]string{"127.0.0.1"}, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour)))
+		defer server.Close()
+
+		Expect(get(server)).To(MatchError(ContainSubstring("expired")))
+	})
+
+	It("generates self-signed certificates nothing trusts", func() {
+		server := serve(SelfSigned([]string{"127.0.0.1"}))
+		defer server.Close()
+
+		Expect(get(server)).To(MatchError(ContainSubstring("unknown authority")))
+	})
+})
@@ -0,0 +1,182 @@
+package standin
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// throttleInterval is how often ThrottleFault reads the next part of a body.
+const throttleInterval = 100 * time.Millisecond
+
+// Fault answers a request in place of, or by mangling the response of, the
+// handler that would otherwise have answered it.
+type Fault func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc)
+
+type injection struct {
+	fault Fault
+
+	// requests picks which of the route's requests fail; nil means all.
+	requests map[int]bool
+	seen     int
+}
+
+// Inject makes requests with method and path fail with fault, whether or not
+// the route has a handler. requests picks which of them fail, counting from 1
+// from now on; all of them fail when none are given.
+func (s *Server) Inject(method, path string, fault Fault, requests ...int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := &injection{fault: fault}
+	if len(requests) > 0 {
+		i.requests = map[int]bool{}
+		for _, n := range requests {
+			i.requests[n] = true
+		}
+	}
+	s.injections[method+" "+path] = i
+}
+
+// ClearFaults stops injecting faults into every route.
+func (s *Server) ClearFaults() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.injections = map[string]*injection{}
+}
+
+// faultFor must be called with the mutex held.
+func (s *Server) faultFor(route string) Fault {
+	i, ok := s.injections[route]
+	if !ok {
+		return nil
+	}
+
+	i.seen++
+	if i.requests != nil && !i.requests[i.seen] {
+		return nil
+	}
+	return i.fault
+}
+
+// StatusFault answers with statusCode and body, e.g. the HTML error page of
+// a router or load balancer in front of the Cloud Controller.
+func StatusFault(statusCode int, contentType, body string) Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}
+}
+
+// CCErrorFault answers with a Cloud Controller error body.
+func CCErrorFault(statusCode, code int, errorCode, description string) Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		WriteCCError(w, statusCode, code, errorCode, description)
+	}
+}
+
+// UAAErrorFault answers with a UAA error body.
+func UAAErrorFault(statusCode int, code, description string) Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		writeUAAError(w, statusCode, code, description)
+	}
+}
+
+// TruncatedFault answers with the first half of the handler's response body
+// and its status, as a complete response, so the client gets cut-off JSON
+// rather than a read error.
+func TruncatedFault() Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		recorder := httptest.NewRecorder()
+		handler(recorder, r)
+
+		for name, values := range recorder.Header() {
+			if name != "Content-Length" {
+				w.Header()[name] = values
+			}
+		}
+		w.WriteHeader(recorder.Code)
+
+		body := recorder.Body.Bytes()
+		w.Write(body[:len(body)/2])
+	}
+}
+
+// HangFault sends nothing for d, then drops the connection. It gives up
+// early when the client goes away.
+func HangFault(d time.Duration) Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}
+}
+
+// ThrottleFault reads the request body at about bytesPerSecond, like an
+// upload over a slow link, before the handler answers. It gives up when the
+// client goes away.
+func ThrottleFault(bytesPerSecond int) Fault {
+	chunk := int64(bytesPerSecond) * int64(throttleInterval) / int64(time.Second)
+	if chunk < 1 {
+		chunk = 1
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		body := &bytes.Buffer{}
+		for {
+			_, err := io.CopyN(body, r.Body, chunk)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-time.After(throttleInterval):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		r.Body = ioutil.NopCloser(body)
+		handler(w, r)
+	}
+}
+
+// ResetFault drops the connection with a TCP reset before answering.
+func ResetFault() Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			tcpConn.SetLinger(0)
+		}
+		conn.Close()
+	}
+}
+
+// RedirectFault redirects the request to the same path and query on base,
+// e.g. a single-label host the CLI can't work out a base domain for.
+func RedirectFault(base string) Fault {
+	return func(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+		http.Redirect(w, r, base+r.URL.RequestURI(), http.StatusFound)
+	}
+}
@@ -0,0 +1,145 @@
+package standin_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fault injection", func() {
+	var server *Server
+
+	get := func() (*http.Response, string, error) {
+		response, err := http.Get(server.URL() + "/v2/info")
+		if err != nil {
+			return nil, "", err
+		}
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		return response, string(body), err
+	}
+
+	BeforeEach(func() {
+		server = New()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("fails only the picked requests of a route", func() {
+		server.Inject("GET", "/v2/info", StatusFault(http.StatusServiceUnavailable, "text/html", "<html>down</html>"), 2)
+
+		var statuses []int
+		for i := 0; i < 3; i++ {
+			response, _, err := get()
+			Expect(err).NotTo(HaveOccurred())
+			statuses = append(statuses, response.StatusCode)
+		}
+
+		Expect(statuses).To(Equal([]int{200, 503, 200}))
+	})
+
+	It("fails every request until cleared", func() {
+		server.Inject("GET", "/v2/info", CCErrorFault(http.StatusTooManyRequests, 10013, "CF-RateLimitExceeded", "Rate Limit Exceeded"))
+
+		response, body, err := get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(body).To(ContainSubstring(`"error_code":"CF-RateLimitExceeded"`))
+
+		server.ClearFaults()
+		response, _, err = get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("injects into routes without a handler", func() {
+		server.Inject("POST", "/v2/spaces", CCErrorFault(http.StatusForbidden, 10003, "CF-NotAuthorized", "You are not authorized to perform the requested action"))
+
+		response, err := http.Post(server.URL()+"/v2/spaces", "application/json", nil)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+	})
+
+	It("truncates the handler's response", func() {
+		_, whole, err := get()
+		Expect(err).NotTo(HaveOccurred())
+
+		server.Inject("GET", "/v2/info", TruncatedFault())
+		_, truncated, err := get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(truncated).To(Equal(whole[:len(whole)/2]))
+	})
+
+	It("drops the connection", func() {
+		server.Inject("GET", "/v2/info", ResetFault())
+
+		_, _, err := get()
+		Expect(err).To(HaveOccurred())
+		Expect(server.Requests()[0].StatusCode).To(BeZero())
+	})
+
+	It("hangs before dropping the connection", func() {
+		server.Inject("GET", "/v2/info", HangFault(200*time.Millisecond))
+
+		started := time.Now()
+		_, _, err := get()
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(started)).To(BeNumerically(">=", 200*time.Millisecond))
+	})
+
+	It("redirects to the same request on another host", func() {
+		server.Inject("GET", "/v2/info", RedirectFault("http://localhost:1"))
+
+		client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		response, err := client.Get(server.URL() + "/v2/info?q=name:gats")
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+
+		Expect(response.StatusCode).To(Equal(http.StatusFound))
+		Expect(response.Header.Get("Location")).To(Equal("http://localhost:1/v2/info?q=name:gats"))
+	})
+
+	It("reads the body slowly and counts the request as in flight meanwhile", func() {
+		var received []byte
+		server.Handle("PUT", "/v2/apps/gats-app/bits", func(w http.ResponseWriter, r *http.Request) {
+			received, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		})
+		server.Inject("PUT", "/v2/apps/gats-app/bits", ThrottleFault(1000))
+
+		body := bytes.Repeat([]byte("gats"), 100)
+		request, err := http.NewRequest("PUT", server.URL()+"/v2/apps/gats-app/bits", bytes.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		started := time.Now()
+		done := make(chan *http.Response, 1)
+		go func() {
+			defer GinkgoRecover()
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+			done <- response
+		}()
+
+		Eventually(func() int { return server.InFlight("PUT", "/v2/apps/gats-app/bits") }).Should(Equal(1))
+
+		var response *http.Response
+		Eventually(done, 5*time.Second).Should(Receive(&response))
+		Expect(response.StatusCode).To(Equal(http.StatusCreated))
+		Expect(time.Since(started)).To(BeNumerically(">=", 300*time.Millisecond))
+		Expect(received).To(Equal(body))
+		Expect(server.InFlight("PUT", "/v2/apps/gats-app/bits")).To(BeZero())
+	})
+})
@@ -0,0 +1,104 @@
+package standin
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxResultsPerPage is the largest page the Cloud Controller serves.
+const maxResultsPerPage = 100
+
+// Paginated serves resources the way the Cloud Controller pages a v2 list:
+// perPage at a time unless the request asks for another results-per-page,
+// with each page's next_url pointing at the following one. A `q=name:<name>`
+// filter narrows the list to the resources with that name.
+func Paginated(resources []interface{}, perPage int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		selected := resources
+		for _, q := range query["q"] {
+			if strings.HasPrefix(q, "name:") {
+				selected = named(selected, strings.TrimPrefix(q, "name:"))
+			}
+		}
+
+		size := perPage
+		if requested, err := strconv.Atoi(query.Get("results-per-page")); err == nil && requested > 0 {
+			size = requested
+		}
+		if size > maxResultsPerPage {
+			size = maxResultsPerPage
+		}
+
+		page, err := strconv.Atoi(query.Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+
+		WriteJSON(w, http.StatusOK, ListPage(r.URL.Path, query, selected, page, size))
+	}
+}
+
+// ListPage renders page (from 1) of a v2 list of resources split into pages
+// of perPage, linking the neighbouring pages under path with query.
+func ListPage(path string, query url.Values, resources []interface{}, page, perPage int) map[string]interface{} {
+	totalPages := (len(resources) + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	from := (page - 1) * perPage
+	if from > len(resources) {
+		from = len(resources)
+	}
+	to := from + perPage
+	if to > len(resources) {
+		to = len(resources)
+	}
+
+	pageURL := func(n int) interface{} {
+		if n < 1 || n > totalPages {
+			return nil
+		}
+
+		q := url.Values{}
+		for key, values := range query {
+			q[key] = values
+		}
+		q.Set("order-direction", "asc")
+		q.Set("page", strconv.Itoa(n))
+		q.Set("results-per-page", strconv.Itoa(perPage))
+		return path + "?" + q.Encode()
+	}
+
+	return map[string]interface{}{
+		"total_results": len(resources),
+		"total_pages":   totalPages,
+		"prev_url":      pageURL(page - 1),
+		"next_url":      pageURL(page + 1),
+		"resources":     append([]interface{}{}, resources[from:to]...),
+	}
+}
+
+// named keeps the resources built with Resource whose entity name matches
+// name case-insensitively, as the Cloud Controller's name filter does.
+func named(resources []interface{}, name string) []interface{} {
+	var matching []interface{}
+	for _, resource := range resources {
+		r, ok := resource.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entity, ok := r["entity"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if entityName, ok := entity["name"].(string); ok && strings.ToLower(entityName) == strings.ToLower(name) {
+			matching = append(matching, resource)
+		}
+	}
+	return matching
+}
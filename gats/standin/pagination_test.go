@@ -0,0 +1,80 @@
+package standin_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paginated", func() {
+	var (
+		server    *Server
+		resources []interface{}
+	)
+
+	type page struct {
+		TotalResults int               `json:"total_results"`
+		TotalPages   int               `json:"total_pages"`
+		NextURL      *string           `json:"next_url"`
+		Resources    []json.RawMessage `json:"resources"`
+	}
+
+	get := func(path string) page {
+		response, err := http.Get(server.URL() + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		var p page
+		Expect(json.NewDecoder(response.Body).Decode(&p)).To(Succeed())
+		return p
+	}
+
+	BeforeEach(func() {
+		resources = nil
+		for i := 0; i < 120; i++ {
+			resources = append(resources, Resource(fmt.Sprintf("guid-%d", i), map[string]interface{}{"name": fmt.Sprintf("Thing-%d", i)}))
+		}
+
+		server = New()
+		server.Handle("GET", "/v2/things", Paginated(resources, 50))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("links every page through next_url", func() {
+		path := "/v2/things?inline-relations-depth=1"
+		var counts []int
+		for path != "" {
+			p := get(path)
+			Expect(p.TotalResults).To(Equal(120))
+			Expect(p.TotalPages).To(Equal(3))
+			counts = append(counts, len(p.Resources))
+
+			path = ""
+			if p.NextURL != nil {
+				path = *p.NextURL
+				Expect(path).To(ContainSubstring("inline-relations-depth=1"))
+			}
+		}
+
+		Expect(counts).To(Equal([]int{50, 50, 20}))
+	})
+
+	It("honours results-per-page up to the Cloud Controller's maximum", func() {
+		Expect(get("/v2/things?results-per-page=30").Resources).To(HaveLen(30))
+		Expect(get("/v2/things?results-per-page=500").Resources).To(HaveLen(100))
+	})
+
+	It("filters by name", func() {
+		p := get("/v2/things?q=name:thing-7")
+		Expect(p.TotalResults).To(Equal(1))
+		Expect(p.NextURL).To(BeNil())
+	})
+})
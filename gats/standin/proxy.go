@@ -0,0 +1,268 @@
+package standin
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyConnection is one request the proxy handled: a CONNECT tunnel, or a
+// plain HTTP request it forwarded.
+type ProxyConnection struct {
+	Method string
+
+	// Target is the host:port the client asked the proxy to reach.
+	Target string
+
+	// Username is who the client authenticated as, if anyone.
+	Username string
+
+	StatusCode int
+}
+
+// Proxy is a local forward proxy that records every connection made through
+// it. It can resolve names that don't exist outside the suite, so a stand-in
+// advertised under such a name is only reachable through the proxy.
+type Proxy struct {
+	server *httptest.Server
+
+	mutex       sync.Mutex
+	hosts       map[string]string
+	username    string
+	password    string
+	refuse      bool
+	connections []ProxyConnection
+}
+
+// NewProxy starts a proxy on a loopback port.
+func NewProxy() *Proxy {
+	p := &Proxy{hosts: map[string]string{}}
+	p.server = httptest.NewServer(http.HandlerFunc(p.serveHTTP))
+	return p
+}
+
+func (p *Proxy) URL() string {
+	return p.server.URL
+}
+
+// URLWithCredentials is the proxy URL with credentials, as http_proxy and
+// https_proxy accept them.
+func (p *Proxy) URLWithCredentials(username, password string) string {
+	u, err := url.Parse(p.server.URL)
+	if err != nil {
+		panic(err)
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String()
+}
+
+func (p *Proxy) Close() {
+	p.server.Close()
+}
+
+// Resolve makes the proxy connect to ip whenever a client asks for host.
+func (p *Proxy) Resolve(host, ip string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.hosts[host] = ip
+}
+
+// RequireAuth answers 407 to every request without these credentials.
+func (p *Proxy) RequireAuth(username, password string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.username, p.password = username, password
+}
+
+// Refuse answers 403 to every request, like a proxy whose policy forbids
+// the destination.
+func (p *Proxy) Refuse() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.refuse = true
+}
+
+// Connections lists every request the proxy handled, in order.
+func (p *Proxy) Connections() []ProxyConnection {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]ProxyConnection{}, p.connections...)
+}
+
+// Targets lists the distinct host:port targets the proxy was asked to reach.
+func (p *Proxy) Targets() []string {
+	seen := map[string]bool{}
+	var targets []string
+	for _, connection := range p.Connections() {
+		if !seen[connection.Target] {
+			seen[connection.Target] = true
+			targets = append(targets, connection.Target)
+		}
+	}
+
+	sort.Strings(targets)
+	return targets
+}
+
+func (p *Proxy) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	connection := ProxyConnection{Method: r.Method, Target: target(r)}
+
+	var ok bool
+	connection.Username, ok = p.admit(r)
+	switch {
+	case p.refusing():
+		connection.StatusCode = http.StatusForbidden
+		http.Error(w, "Forbidden by gats proxy", connection.StatusCode)
+	case !ok:
+		connection.StatusCode = http.StatusProxyAuthRequired
+		w.Header().Set("Proxy-Authenticate", `Basic realm="gats"`)
+		http.Error(w, "Proxy authentication required", connection.StatusCode)
+	case r.Method == "CONNECT":
+		connection.StatusCode = p.tunnel(w, r)
+	default:
+		connection.StatusCode = p.forward(w, r)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.connections = append(p.connections, connection)
+}
+
+func (p *Proxy) refusing() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.refuse
+}
+
+// admit checks the Proxy-Authorization header against the required
+// credentials, and returns whoever it names.
+func (p *Proxy) admit(r *http.Request) (string, bool) {
+	p.mutex.Lock()
+	username, password := p.username, p.password
+	p.mutex.Unlock()
+
+	givenUsername, givenPassword := "", ""
+	authorization := r.Header.Get("Proxy-Authorization")
+	if strings.HasPrefix(authorization, "Basic ") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(authorization, "Basic "))
+		if err == nil {
+			credentials := strings.SplitN(string(decoded), ":", 2)
+			if len(credentials) == 2 {
+				givenUsername, givenPassword = credentials[0], credentials[1]
+			}
+		}
+	}
+
+	if username == "" {
+		return givenUsername, true
+	}
+	return givenUsername, givenUsername == username && givenPassword == password
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) int {
+	upstream, err := p.dial("tcp", r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return http.StatusBadGateway
+	}
+
+	client, buffered, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		upstream.Close()
+		return http.StatusInternalServerError
+	}
+
+	client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	go func() {
+		<-done
+		client.Close()
+		upstream.Close()
+	}()
+
+	return http.StatusOK
+}
+
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) int {
+	request, err := http.NewRequest(r.Method, r.URL.String(), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return http.StatusBadRequest
+	}
+	for name, values := range r.Header {
+		if name != "Proxy-Authorization" && name != "Proxy-Connection" {
+			request.Header[name] = values
+		}
+	}
+
+	transport := &http.Transport{Dial: p.dial}
+	defer transport.CloseIdleConnections()
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return http.StatusBadGateway
+	}
+	defer response.Body.Close()
+
+	for name, values := range response.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(response.StatusCode)
+	io.Copy(w, response.Body)
+
+	return response.StatusCode
+}
+
+func (p *Proxy) dial(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	if ip, ok := p.hosts[host]; ok {
+		host = ip
+	}
+	p.mutex.Unlock()
+
+	return net.DialTimeout(network, net.JoinHostPort(host, port), 10*time.Second)
+}
+
+// target is the host:port a request asks the proxy to reach.
+func target(r *http.Request) string {
+	if r.Method == "CONNECT" {
+		return r.Host
+	}
+
+	host := r.URL.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		port := "80"
+		if r.URL.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(host, port)
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+package standin_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strings"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Proxy", func() {
+	var (
+		proxy  *Proxy
+		server *Server
+	)
+
+	get := func(proxyURL string) (*http.Response, error) {
+		u, err := url.Parse(proxyURL)
+		Expect(err).NotTo(HaveOccurred())
+
+		client := &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(u),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+		response, err := client.Get(server.URL() + "/v2/info")
+		if err == nil {
+			response.Body.Close()
+		}
+		return response, err
+	}
+
+	BeforeEach(func() {
+		proxy = NewProxy()
+
+		server = NewUnstarted()
+		server.Host = "gats-standin.invalid"
+		proxy.Resolve(server.Host, "127.0.0.1")
+	})
+
+	AfterEach(func() {
+		proxy.Close()
+		server.Close()
+	})
+
+	It("advertises the stand-in under its Host", func() {
+		server.Start()
+
+		Expect(server.URL()).To(HavePrefix("http://gats-standin.invalid:"))
+		Expect(server.URL()).To(HaveSuffix(server.Addr()[strings.LastIndex(server.Addr(), ":"):]))
+	})
+
+	It("forwards plain HTTP requests to names only it resolves", func() {
+		server.Start()
+
+		response, err := get(proxy.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		connections := proxy.Connections()
+		Expect(connections).To(HaveLen(1))
+		Expect(connections[0].Method).To(Equal("GET"))
+		Expect(connections[0].StatusCode).To(Equal(http.StatusOK))
+		Expect(proxy.Targets()).To(Equal([]string{strings.TrimPrefix(server.URL(), "http://")}))
+	})
+
+	It("tunnels HTTPS through CONNECT", func() {
+		server.StartTLS(nil)
+
+		response, err := get(proxy.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		connections := proxy.Connections()
+		Expect(connections).To(HaveLen(1))
+		Expect(connections[0].Method).To(Equal("CONNECT"))
+		Expect(connections[0].Target).To(Equal(strings.TrimPrefix(server.URL(), "https://")))
+		Expect(server.Requests()).To(HaveLen(1))
+	})
+
+	It("requires credentials when asked to", func() {
+		server.StartTLS(nil)
+		proxy.RequireAuth("proxy-user", "proxy-password")
+
+		_, err := get(proxy.URL())
+		Expect(err).To(MatchError(ContainSubstring("Proxy Authentication Required")))
+
+		_, err = get(proxy.URLWithCredentials("proxy-user", "proxy-password"))
+		Expect(err).NotTo(HaveOccurred())
+
+		connections := proxy.Connections()
+		Expect(connections).To(HaveLen(2))
+		Expect(connections[0].StatusCode).To(Equal(http.StatusProxyAuthRequired))
+		Expect(connections[1].Username).To(Equal("proxy-user"))
+		Expect(connections[1].StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("refuses every request when asked to", func() {
+		server.StartTLS(nil)
+		proxy.Refuse()
+
+		_, err := get(proxy.URL())
+		Expect(err).To(MatchError(ContainSubstring("Forbidden")))
+		Expect(server.Requests()).To(BeEmpty())
+	})
+})
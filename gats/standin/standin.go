@@ -0,0 +1,522 @@
+package standin
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+)
+
+// The single user, org and space every stand-in serves.
+const (
+	Username  = "gats-user"
+	Password  = "gats-password"
+	UserGuid  = "00000000-0000-0000-0000-00000000a001"
+	OrgName   = "gats-org"
+	OrgGuid   = "00000000-0000-0000-0000-00000000a002"
+	SpaceName = "gats-space"
+	SpaceGuid = "00000000-0000-0000-0000-00000000a003"
+
+	APIVersion = "2.54.0"
+)
+
+// Request is one request the stand-in answered.
+type Request struct {
+	Method        string
+	Path          string
+	RawQuery      string
+	Authorization string
+
+	// StatusCode is 0 when a fault dropped the connection instead.
+	StatusCode int
+	Time       time.Time
+}
+
+// Server is a minimal local Cloud Controller and UAA. It serves /v2/info,
+// UAA login and token grants, and the org, space and space summary lookups
+// behind `cf target` and `cf apps`, so the CLI can be exercised without a
+// foundation. Suites add or replace routes with Handle.
+type Server struct {
+	server *httptest.Server
+
+	// Host replaces 127.0.0.1 in the URL the stand-in advertises, e.g. with a
+	// name only a proxy can resolve.
+	Host string
+
+	mutex               sync.Mutex
+	tokenLifetime       time.Duration
+	rotateRefreshTokens bool
+	handlers            map[string]http.HandlerFunc
+	accessTokens        map[string]time.Time
+	refreshTokens       map[string]bool
+	tokenCount          int
+	refreshes           int
+	rejectedRefreshes   int
+	requests            []Request
+	inFlight            map[string]int
+	injections          map[string]*injection
+	warnings            map[string][]string
+}
+
+// NewUnstarted creates a stand-in whose listener isn't serving yet, so a
+// suite can configure TLS before calling Start or StartTLS.
+func NewUnstarted() *Server {
+	s := &Server{
+		tokenLifetime: time.Hour,
+		handlers:      map[string]http.HandlerFunc{},
+		accessTokens:  map[string]time.Time{},
+		refreshTokens: map[string]bool{},
+		inFlight:      map[string]int{},
+		injections:    map[string]*injection{},
+		warnings:      map[string][]string{},
+	}
+	s.server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+
+	s.Handle("GET", "/v2/info", s.info)
+	s.Handle("GET", "/login", s.login)
+	s.Handle("POST", "/oauth/token", s.token)
+	s.Handle("GET", "/v2/organizations", s.authorized(s.organizations))
+	s.Handle("GET", "/v2/organizations/"+OrgGuid+"/spaces", s.authorized(s.spaces))
+	s.Handle("GET", "/v2/spaces/"+SpaceGuid+"/summary", s.authorized(s.spaceSummary))
+
+	return s
+}
+
+// New starts a plain HTTP stand-in.
+func New() *Server {
+	s := NewUnstarted()
+	s.Start()
+	return s
+}
+
+// Listen moves an unstarted stand-in to address, e.g. a non-loopback
+// interface that the CLI doesn't exempt from proxying.
+func (s *Server) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	s.server.Listener.Close()
+	s.server.Listener = listener
+	return nil
+}
+
+func (s *Server) Start() {
+	s.server.Start()
+}
+
+// StartTLS serves HTTPS with config, or with httptest's self-signed
+// certificate when config is nil.
+func (s *Server) StartTLS(config *tls.Config) {
+	s.server.TLS = config
+	s.server.StartTLS()
+}
+
+// URL is where the CLI reaches the stand-in.
+func (s *Server) URL() string {
+	if s.Host == "" {
+		return s.server.URL
+	}
+
+	u, err := url.Parse(s.server.URL)
+	if err != nil {
+		panic(err)
+	}
+	u.Host = net.JoinHostPort(s.Host, u.Port())
+	return u.String()
+}
+
+// Addr is the address the stand-in listens on.
+func (s *Server) Addr() string {
+	return s.server.Listener.Addr().String()
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Handle adds or replaces the handler for requests with method and path
+// (without the query).
+func (s *Server) Handle(method, path string, handler http.HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[method+" "+path] = handler
+}
+
+// Requests lists every request answered so far, in order.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Request{}, s.requests...)
+}
+
+// InFlight is the number of requests with method and path that the stand-in
+// is still answering, e.g. an upload a fault slows down.
+func (s *Server) InFlight(method, path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.inFlight[method+" "+path]
+}
+
+// Refreshes counts the refresh grants that succeeded and that were rejected.
+func (s *Server) Refreshes() (granted, rejected int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.refreshes, s.rejectedRefreshes
+}
+
+// SetTokenLifetime sets how long access tokens issued from now on are
+// accepted. It defaults to an hour.
+func (s *Server) SetTokenLifetime(lifetime time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tokenLifetime = lifetime
+}
+
+// TokenLifetime is how long an issued access token is accepted.
+func (s *Server) TokenLifetime() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.tokenLifetime
+}
+
+// SetRotateRefreshTokens makes every refresh grant revoke the refresh token
+// it was made with, like a UAA configured for refresh token rotation.
+func (s *Server) SetRotateRefreshTokens(rotate bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.rotateRefreshTokens = rotate
+}
+
+// ExpireAccessTokens makes every access token issued so far expire now.
+func (s *Server) ExpireAccessTokens() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for token := range s.accessTokens {
+		s.accessTokens[token] = now
+	}
+}
+
+// RevokeRefreshTokens makes every refresh token issued so far invalid, as if
+// the user's sessions were revoked in the UAA.
+func (s *Server) RevokeRefreshTokens() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.refreshTokens = map[string]bool{}
+}
+
+// IsValidAccessToken reports whether token, with or without its "bearer "
+// prefix, was issued by the stand-in and hasn't expired.
+func (s *Server) IsValidAccessToken(token string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiresAt, ok := s.accessTokens[trimBearer(token)]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// IssueTokens hands out a valid access and refresh token without a grant,
+// for seeding a logged-in CF_HOME.
+func (s *Server) IssueTokens() (accessToken, refreshToken string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.issueTokens()
+}
+
+// SeedCfHome writes a config to cfHome that is logged in as Username and
+// targets OrgName and SpaceName on this stand-in, as `cf api`, `cf auth` and
+// `cf target` would.
+func (s *Server) SeedCfHome(cfHome string) error {
+	accessToken, refreshToken := s.IssueTokens()
+
+	return gatsHelpers.WriteCfConfig(cfHome, gatsHelpers.CfConfigData{
+		Target:                s.URL(),
+		APIVersion:            APIVersion,
+		AuthorizationEndpoint: s.URL(),
+		UaaEndpoint:           s.URL(),
+		LoggregatorEndPoint:   s.loggingEndpoint(),
+		DopplerEndPoint:       strings.Replace(s.loggingEndpoint(), "loggregator", "doppler", 1),
+		AccessToken:           "bearer " + accessToken,
+		RefreshToken:          refreshToken,
+		OrganizationFields:    gatsHelpers.CfOrganizationFields{GUID: OrgGuid, Name: OrgName},
+		SpaceFields:           gatsHelpers.CfSpaceFields{GUID: SpaceGuid, Name: SpaceName},
+		SSLDisabled:           strings.HasPrefix(s.URL(), "https"),
+		AsyncTimeout:          5,
+	})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	handler, ok := s.handlers[r.Method+" "+r.URL.Path]
+	if !ok {
+		handler = notFound
+	}
+	fault := s.faultFor(r.Method + " " + r.URL.Path)
+	warnings := s.warnings[r.Method+" "+r.URL.Path]
+	s.inFlight[r.Method+" "+r.URL.Path]++
+	s.mutex.Unlock()
+
+	for _, warning := range warnings {
+		w.Header().Add("X-Cf-Warnings", url.QueryEscape(warning))
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	if fault != nil {
+		fault(recorder, r, handler)
+	} else {
+		handler(recorder, r)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inFlight[r.Method+" "+r.URL.Path]--
+	s.requests = append(s.requests, Request{
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		RawQuery:      r.URL.RawQuery,
+		Authorization: r.Header.Get("Authorization"),
+		StatusCode:    recorder.statusCode,
+		Time:          time.Now(),
+	})
+}
+
+// authorized rejects requests whose bearer token is unknown or expired the
+// way the Cloud Controller does, which makes the CLI refresh its token.
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.IsValidAccessToken(r.Header.Get("Authorization")) {
+			WriteCCError(w, http.StatusUnauthorized, 1000, "CF-InvalidAuthToken", "Invalid Auth Token")
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func trimBearer(token string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(token, "bearer "), "Bearer ")
+}
+
+func (s *Server) loggingEndpoint() string {
+	return "wss://loggregator.gats-standin.example.com:443"
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"name":                   "gats-standin",
+		"api_version":            APIVersion,
+		"authorization_endpoint": s.URL(),
+		"token_endpoint":         s.URL(),
+		"logging_endpoint":       s.loggingEndpoint(),
+		"min_cli_version":        "",
+	})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"prompts": map[string][]string{
+			"username": {"text", "Email"},
+			"password": {"password", "Password"},
+		},
+		"links": map[string]string{
+			"uaa":   s.URL(),
+			"login": s.URL(),
+		},
+	})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeUAAError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		if r.PostForm.Get("username") != Username || r.PostForm.Get("password") != Password {
+			writeUAAError(w, http.StatusUnauthorized, "unauthorized", "Bad credentials")
+			return
+		}
+	case "refresh_token":
+		refreshToken := r.PostForm.Get("refresh_token")
+		if !s.refreshTokens[refreshToken] {
+			s.rejectedRefreshes++
+			writeUAAError(w, http.StatusUnauthorized, "invalid_token", "Invalid refresh token")
+			return
+		}
+		if s.rotateRefreshTokens {
+			delete(s.refreshTokens, refreshToken)
+		}
+		s.refreshes++
+	default:
+		writeUAAError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type")
+		return
+	}
+
+	accessToken, refreshToken := s.issueTokens()
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "bearer",
+		"refresh_token": refreshToken,
+		"expires_in":    int(s.tokenLifetime.Seconds()),
+		"scope":         "cloud_controller.read cloud_controller.write openid",
+		"jti":           fmt.Sprintf("gats-%d", s.tokenCount),
+	})
+}
+
+// issueTokens must be called with the mutex held.
+func (s *Server) issueTokens() (string, string) {
+	s.tokenCount++
+	expiresAt := time.Now().Add(s.tokenLifetime)
+
+	// The count keeps every token distinct even when two are issued within
+	// the same second.
+	accessToken := fmt.Sprintf("%s%d", gatsHelpers.UnsignedAccessToken(Username, UserGuid, expiresAt), s.tokenCount)
+	refreshToken := fmt.Sprintf("gats-refresh-token-%d", s.tokenCount)
+
+	s.accessTokens[accessToken] = expiresAt
+	s.refreshTokens[refreshToken] = true
+	return accessToken, refreshToken
+}
+
+func (s *Server) organizations(w http.ResponseWriter, r *http.Request) {
+	if !matchesNameFilter(r.URL.Query(), OrgName) {
+		WriteJSON(w, http.StatusOK, ListResponse(nil))
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, ListResponse([]interface{}{
+		Resource(OrgGuid, map[string]interface{}{
+			"name":             OrgName,
+			"quota_definition": Resource("00000000-0000-0000-0000-00000000a004", map[string]interface{}{"name": "default"}),
+		}),
+	}))
+}
+
+func (s *Server) spaces(w http.ResponseWriter, r *http.Request) {
+	if !matchesNameFilter(r.URL.Query(), SpaceName) {
+		WriteJSON(w, http.StatusOK, ListResponse(nil))
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, ListResponse([]interface{}{
+		Resource(SpaceGuid, map[string]interface{}{
+			"name":              SpaceName,
+			"organization_guid": OrgGuid,
+			"allow_ssh":         true,
+		}),
+	}))
+}
+
+func (s *Server) spaceSummary(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"guid":     SpaceGuid,
+		"name":     SpaceName,
+		"apps":     []interface{}{},
+		"services": []interface{}{},
+	})
+}
+
+// matchesNameFilter reports whether a `q=name:<name>` filter, if any, selects name.
+func matchesNameFilter(query url.Values, name string) bool {
+	for _, q := range query["q"] {
+		if strings.HasPrefix(q, "name:") && strings.TrimPrefix(q, "name:") != strings.ToLower(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Resource renders a v2 resource with guid as its metadata.
+func Resource(guid string, entity map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"guid": guid,
+			"url":  "/v2/resources/" + guid,
+		},
+		"entity": entity,
+	}
+}
+
+// ListResponse renders a single page v2 list.
+func ListResponse(resources []interface{}) map[string]interface{} {
+	if resources == nil {
+		resources = []interface{}{}
+	}
+
+	return map[string]interface{}{
+		"total_results": len(resources),
+		"total_pages":   1,
+		"prev_url":      nil,
+		"next_url":      nil,
+		"resources":     resources,
+	}
+}
+
+func WriteJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+// WriteCCError writes a Cloud Controller error body.
+func WriteCCError(w http.ResponseWriter, statusCode, code int, errorCode, description string) {
+	WriteJSON(w, statusCode, map[string]interface{}{
+		"code":        code,
+		"description": description,
+		"error_code":  errorCode,
+	})
+}
+
+func writeUAAError(w http.ResponseWriter, statusCode int, code, description string) {
+	WriteJSON(w, statusCode, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	WriteCCError(w, http.StatusNotFound, 10000, "CF-NotFound", "Unknown request")
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Hijack takes over the connection for faults that drop it, which leaves
+// the request recorded without a status code.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.statusCode = 0
+	return r.ResponseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,14 @@
+package standin_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStandin(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Stand-in Suite")
+}
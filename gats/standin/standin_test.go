@@ -0,0 +1,149 @@
+package standin_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var server *Server
+
+	get := func(path, accessToken string) (int, map[string]interface{}) {
+		request, err := http.NewRequest("GET", server.URL()+path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if accessToken != "" {
+			request.Header.Set("Authorization", "bearer "+accessToken)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body := map[string]interface{}{}
+		Expect(json.NewDecoder(response.Body).Decode(&body)).To(Succeed())
+		return response.StatusCode, body
+	}
+
+	grant := func(form url.Values) (int, map[string]interface{}) {
+		response, err := http.Post(server.URL()+"/oauth/token", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body := map[string]interface{}{}
+		Expect(json.NewDecoder(response.Body).Decode(&body)).To(Succeed())
+		return response.StatusCode, body
+	}
+
+	BeforeEach(func() {
+		server = New()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("advertises itself as the authorization endpoint", func() {
+		status, info := get("/v2/info", "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(info["api_version"]).To(Equal(APIVersion))
+		Expect(info["authorization_endpoint"]).To(Equal(server.URL()))
+	})
+
+	It("grants tokens for the stand-in user only", func() {
+		status, _ := grant(url.Values{"grant_type": {"password"}, "username": {Username}, "password": {"wrong"}})
+		Expect(status).To(Equal(http.StatusUnauthorized))
+
+		status, tokens := grant(url.Values{"grant_type": {"password"}, "username": {Username}, "password": {Password}})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(tokens["token_type"]).To(Equal("bearer"))
+
+		status, orgs := get("/v2/organizations?q=name:gats-org", tokens["access_token"].(string))
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(orgs["total_results"]).To(BeEquivalentTo(1))
+	})
+
+	It("rejects expired tokens the way the Cloud Controller does", func() {
+		server.SetTokenLifetime(time.Millisecond)
+		accessToken, _ := server.IssueTokens()
+		time.Sleep(5 * time.Millisecond)
+
+		status, body := get("/v2/spaces/"+SpaceGuid+"/summary", accessToken)
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(body["code"]).To(BeEquivalentTo(1000))
+	})
+
+	It("expires access tokens and revokes refresh tokens on demand", func() {
+		accessToken, refreshToken := server.IssueTokens()
+		Expect(server.IsValidAccessToken("bearer " + accessToken)).To(BeTrue())
+
+		server.ExpireAccessTokens()
+		Expect(server.IsValidAccessToken(accessToken)).To(BeFalse())
+
+		server.RevokeRefreshTokens()
+		status, body := grant(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(body["error"]).To(Equal("invalid_token"))
+	})
+
+	It("revokes used refresh tokens when rotating", func() {
+		server.SetRotateRefreshTokens(true)
+		_, refreshToken := server.IssueTokens()
+
+		status, _ := grant(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body := grant(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(body["error"]).To(Equal("invalid_token"))
+
+		granted, rejected := server.Refreshes()
+		Expect(granted).To(Equal(1))
+		Expect(rejected).To(Equal(1))
+	})
+
+	It("records every request and serves added routes", func() {
+		server.Handle("GET", "/v2/gats", func(w http.ResponseWriter, r *http.Request) {
+			WriteJSON(w, http.StatusTeapot, map[string]string{"gats": "yes"})
+		})
+
+		status, _ := get("/v2/gats?x=1", "")
+		Expect(status).To(Equal(http.StatusTeapot))
+
+		status, _ = get("/v2/unknown", "")
+		Expect(status).To(Equal(http.StatusNotFound))
+
+		requests := server.Requests()
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].Path).To(Equal("/v2/gats"))
+		Expect(requests[0].RawQuery).To(Equal("x=1"))
+		Expect(requests[0].StatusCode).To(Equal(http.StatusTeapot))
+		Expect(requests[1].StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("seeds a CF_HOME that is logged in and targeted", func() {
+		cfHome, err := ioutil.TempDir("", "gats-standin")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(cfHome)
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+
+		data, err := gatsHelpers.ReadCfConfig(cfHome)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Target).To(Equal(server.URL()))
+		Expect(data.SpaceFields.Name).To(Equal(SpaceName))
+
+		status, _ := get("/v2/spaces/"+SpaceGuid+"/summary", strings.TrimPrefix(data.AccessToken, "bearer "))
+		Expect(status).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,20 @@
+package standin
+
+// Warn makes responses to method and path carry warnings in X-Cf-Warnings
+// headers, one header per warning, the way the Cloud Controller reports
+// deprecations and other advisories. Repeating a warning repeats the header.
+func (s *Server) Warn(method, path string, warnings ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	route := method + " " + path
+	s.warnings[route] = append(s.warnings[route], warnings...)
+}
+
+// ClearWarnings stops adding warnings to every route.
+func (s *Server) ClearWarnings() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.warnings = map[string][]string{}
+}
@@ -0,0 +1,45 @@
+package standin_test
+
+import (
+	"net/http"
+
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("warnings", func() {
+	var server *Server
+
+	get := func() *http.Response {
+		response, err := http.Get(server.URL() + "/v2/info")
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		return response
+	}
+
+	BeforeEach(func() {
+		server = New()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sends one escaped X-Cf-Warnings header per warning", func() {
+		server.Warn("GET", "/v2/info", "Quota almost used up: 90%", "Quota almost used up: 90%")
+
+		Expect(get().Header["X-Cf-Warnings"]).To(Equal([]string{
+			"Quota+almost+used+up%3A+90%25",
+			"Quota+almost+used+up%3A+90%25",
+		}))
+	})
+
+	It("stops warning once cleared", func() {
+		server.Warn("GET", "/v2/info", "Endpoint deprecated")
+		server.ClearWarnings()
+
+		Expect(get().Header).NotTo(HaveKey("X-Cf-Warnings"))
+	})
+})
@@ -0,0 +1,159 @@
+package strict
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/runner"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/gomega/gexec"
+)
+
+// unfinishedGrace bounds how long AfterEach waits for a command the spec
+// left running before checking it for warnings anyway.
+const unfinishedGrace = 5 * time.Second
+
+// RegisterHooks runs every cf invocation of the calling suite with
+// CF_RAISE_ERROR_ON_WARNINGS when GATS_STRICT_WARNINGS is set, and fails
+// each spec whose invocations raised warnings, naming the invocations. Call
+// it from a top-level `var _ =`, like cassette.RegisterHooks.
+//
+// Ginkgo keeps only the first failure of a spec, and a raised warning usually
+// fails the spec's own exit code assertion first. So each violation is also
+// written to GinkgoWriter as soon as its invocation exits, and the summary is
+// written there before the AfterEach fails.
+func RegisterHooks() bool {
+	if os.Getenv(EnvVar) == "" {
+		return false
+	}
+
+	originalInterceptor := runner.CommandInterceptor
+	runner.CommandInterceptor = func(cmd *exec.Cmd) *exec.Cmd {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, RaiseErrorEnvVar+"=true")
+		return originalInterceptor(cmd)
+	}
+
+	watcher := &watcher{}
+
+	BeforeEach(func() {
+		watcher.install()
+		watcher.start()
+	})
+
+	AfterEach(func() {
+		violations := watcher.finish()
+		if len(violations) == 0 {
+			return
+		}
+
+		messages := make([]string, len(violations))
+		for i, violation := range violations {
+			messages[i] = violation.String()
+		}
+		message := fmt.Sprintf("%s is set and %d cf invocation(s) raised warnings:\n%s", EnvVar, len(violations), strings.Join(messages, "\n"))
+		fmt.Fprintln(GinkgoWriter, message)
+		Fail(message)
+	})
+
+	return true
+}
+
+type watchedSession struct {
+	args    []string
+	session *gexec.Session
+}
+
+type watcher struct {
+	installed bool
+
+	mutex    sync.Mutex
+	watching bool
+	sessions []watchedSession
+	stop     chan struct{}
+}
+
+// install wraps cf.Cf and cf.CfAuth. It runs from the first BeforeEach so
+// that it wraps whatever the suite installed (e.g. redaction) before RunSpecs.
+func (w *watcher) install() {
+	if w.installed {
+		return
+	}
+	w.installed = true
+
+	originalCf := cf.Cf
+	cf.Cf = func(args ...string) *gexec.Session {
+		return w.watch(gatsHelpers.RedactArgs(args), originalCf(args...))
+	}
+
+	originalCfAuth := cf.CfAuth
+	cf.CfAuth = func(user, password string) *gexec.Session {
+		return w.watch(gatsHelpers.RedactArgs([]string{"auth", user, password}), originalCfAuth(user, password))
+	}
+}
+
+func (w *watcher) watch(args []string, session *gexec.Session) *gexec.Session {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.watching {
+		w.sessions = append(w.sessions, watchedSession{args: args, session: session})
+		go report(args, session, w.stop)
+	}
+	return session
+}
+
+// report writes the warnings an invocation raised to GinkgoWriter when it
+// exits, unless the spec finished first.
+func report(args []string, session *gexec.Session, stop <-chan struct{}) {
+	select {
+	case <-session.Exited:
+	case <-stop:
+		return
+	}
+
+	if warnings, raised := ParseRaisedWarnings(string(session.Out.Contents()) + string(session.Err.Contents())); raised {
+		fmt.Fprintf(GinkgoWriter, "%s is set and %s\n", EnvVar, Violation{Args: args, Warnings: warnings})
+	}
+}
+
+func (w *watcher) start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.watching = true
+	w.sessions = nil
+	w.stop = make(chan struct{})
+}
+
+func (w *watcher) finish() []Violation {
+	w.mutex.Lock()
+	sessions := w.sessions
+	stop := w.stop
+	w.watching = false
+	w.sessions = nil
+	w.mutex.Unlock()
+	defer close(stop)
+
+	var violations []Violation
+	for _, s := range sessions {
+		select {
+		case <-s.session.Exited:
+		case <-time.After(unfinishedGrace):
+		}
+
+		warnings, raised := ParseRaisedWarnings(string(s.session.Out.Contents()) + string(s.session.Err.Contents()))
+		if raised {
+			violations = append(violations, Violation{Args: s.args, Warnings: warnings})
+		}
+	}
+	return violations
+}
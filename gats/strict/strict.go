@@ -0,0 +1,59 @@
+package strict
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// EnvVar turns on strict mode for a run.
+	EnvVar = "GATS_STRICT_WARNINGS"
+
+	// RaiseErrorEnvVar makes the CLI's WarningsCollector panic with the
+	// warnings it collected instead of printing them.
+	RaiseErrorEnvVar = "CF_RAISE_ERROR_ON_WARNINGS"
+
+	crashDialogSignature = "Something unexpected happened"
+)
+
+// errorSection is the Error section of the panic printer's crash dialog.
+var errorSection = regexp.MustCompile(`(?s)\n\s*Error\n(.*?)\n\s*Stack Trace\n`)
+
+// Violation is a cf invocation that raised warnings in strict mode.
+type Violation struct {
+	Args     []string
+	Warnings []string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("cf %s raised warnings:\n    %s", strings.Join(v.Args, " "), strings.Join(v.Warnings, "\n    "))
+}
+
+// ParseRaisedWarnings finds the crash dialog the CLI prints when
+// CF_RAISE_ERROR_ON_WARNINGS turns warnings into a panic, and returns the
+// warnings in it. Crash dialogs for any other panic don't count.
+func ParseRaisedWarnings(output string) ([]string, bool) {
+	start := strings.Index(output, crashDialogSignature)
+	if start < 0 {
+		return nil, false
+	}
+
+	dialog := output[start:]
+	if !strings.Contains(dialog, "PrintWarnings") {
+		return nil, false
+	}
+
+	match := errorSection.FindStringSubmatch(dialog)
+	if match == nil {
+		return nil, false
+	}
+
+	var warnings []string
+	for _, line := range strings.Split(match[1], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			warnings = append(warnings, line)
+		}
+	}
+	return warnings, true
+}
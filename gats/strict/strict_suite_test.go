@@ -0,0 +1,13 @@
+package strict_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Strict Suite")
+}
@@ -0,0 +1,65 @@
+package strict_test
+
+import (
+	. "code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// crashDialog is laid out like panicprinter.CrashDialog.
+func crashDialog(message, stackTrace string) string {
+	return `
+	Something unexpected happened. This is a bug in cf.
+
+	Please re-run the command that caused this exception with the environment
+	variable CF_TRACE set to true.
+
+	Include the below information when creating the issue:
+
+		Command
+		cf apps
+
+		CLI Version
+		6.22.0+abcdef0
+
+		Error
+		` + message + `
+
+		Stack Trace
+		` + stackTrace + `
+
+		Your Platform Details
+		e.g. Mac OS X 10.11, Windows 8.1 64-bit, Ubuntu 14.04.3 64-bit
+`
+}
+
+var _ = Describe("ParseRaisedWarnings", func() {
+	It("returns the warnings a strict run panicked with", func() {
+		output := "Getting apps in org o / space s as admin...\n" + crashDialog(
+			"Space quota is almost used up\nThe v2 summary endpoint is going away",
+			"goroutine 1 [running]:\ngithub.com/cloudfoundry/cli/cf/net.WarningsCollector.PrintWarnings(...)\n",
+		)
+
+		warnings, raised := ParseRaisedWarnings(output)
+		Expect(raised).To(BeTrue())
+		Expect(warnings).To(Equal([]string{"Space quota is almost used up", "The v2 summary endpoint is going away"}))
+	})
+
+	It("ignores crash dialogs for other panics", func() {
+		output := crashDialog("runtime error: invalid memory address", "goroutine 1 [running]:\nmain.main()\n")
+
+		_, raised := ParseRaisedWarnings(output)
+		Expect(raised).To(BeFalse())
+	})
+
+	It("ignores output without a crash dialog", func() {
+		_, raised := ParseRaisedWarnings("OK\n\nNo apps found\n")
+		Expect(raised).To(BeFalse())
+	})
+
+	It("names the invocation in violations", func() {
+		violation := Violation{Args: []string{"apps"}, Warnings: []string{"first", "second"}}
+		Expect(violation.String()).To(Equal("cf apps raised warnings:\n    first\n    second"))
+	})
+})
@@ -0,0 +1,136 @@
+package tokens_test
+
+import (
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const commandTimeout = 30 * time.Second
+
+var _ = Describe("access token expiry", func() {
+	var (
+		server *standin.Server
+
+		expiredToken string
+
+		restoreCfHome func()
+	)
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		_, restoreCfHome = gatsHelpers.UseTempCfHome("gats-tokens")
+
+		Eventually(Cf("api", server.URL()), commandTimeout).Should(Exit(0))
+		Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+		Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+		Eventually(Cf("install-plugin", "-f", pluginPath), commandTimeout).Should(Exit(0))
+
+		expiredToken = gatsHelpers.CurrentCfConfig().AccessToken
+		server.ExpireAccessTokens()
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	Context("when the refresh token is still valid", func() {
+		It("refreshes the token and retries the request", func() {
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(0))
+			Expect(session).To(Say("No apps found"))
+
+			granted, rejected := server.Refreshes()
+			Expect(granted).To(Equal(1))
+			Expect(rejected).To(BeZero())
+
+			accessToken := gatsHelpers.CurrentCfConfig().AccessToken
+			Expect(accessToken).NotTo(Equal(expiredToken))
+			Expect(server.IsValidAccessToken(accessToken)).To(BeTrue())
+		})
+
+		It("prints a fresh token from `cf oauth-token`", func() {
+			session := Cf("oauth-token")
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			token := strings.TrimSpace(string(session.Out.Contents()))
+			Expect(token).To(HavePrefix("bearer "))
+			Expect(token).NotTo(Equal(expiredToken))
+			Expect(server.IsValidAccessToken(token)).To(BeTrue())
+			Expect(gatsHelpers.CurrentCfConfig().AccessToken).To(Equal(token))
+		})
+
+		It("gives plugins a fresh token from AccessToken()", func() {
+			session := Cf("AccessToken")
+			Eventually(session, commandTimeout).Should(Exit(0))
+			Expect(session).To(Say("Done AccessToken: bearer "))
+
+			token := strings.TrimSpace(strings.TrimPrefix(string(session.Out.Contents()), "Done AccessToken:"))
+			Expect(token).NotTo(Equal(expiredToken))
+			Expect(server.IsValidAccessToken(token)).To(BeTrue())
+		})
+
+		Context("and the UAA rotates refresh tokens", func() {
+			BeforeEach(func() {
+				server.SetRotateRefreshTokens(true)
+			})
+
+			It("keeps the session across several refreshes", func() {
+				for i := 0; i < 3; i++ {
+					Eventually(Cf("apps"), commandTimeout).Should(Exit(0))
+					server.ExpireAccessTokens()
+				}
+
+				granted, rejected := server.Refreshes()
+				Expect(granted).To(Equal(3))
+				Expect(rejected).To(BeZero())
+			})
+		})
+	})
+
+	Context("when the refresh token has been revoked", func() {
+		BeforeEach(func() {
+			server.RevokeRefreshTokens()
+		})
+
+		It("asks the user to log back in", func() {
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(1))
+			Expect(session).To(Say("Authentication has expired.  Please log back in to re-authenticate."))
+
+			_, rejected := server.Refreshes()
+			Expect(rejected).To(Equal(1))
+		})
+
+		It("fails `cf oauth-token` with the same message", func() {
+			session := Cf("oauth-token")
+			Eventually(session, commandTimeout).Should(Exit(1))
+			Expect(session).To(Say("Authentication has expired.  Please log back in to re-authenticate."))
+			Expect(session).NotTo(Say("bearer "))
+		})
+
+		It("gives plugins no token from AccessToken()", func() {
+			session := Cf("AccessToken")
+			Eventually(session, commandTimeout).Should(Exit(0))
+			Expect(session.Out.Contents()).NotTo(ContainSubstring("bearer "))
+		})
+
+		It("recovers once the user logs back in", func() {
+			Eventually(Cf("apps"), commandTimeout).Should(Exit(1))
+
+			Eventually(CfAuth(standin.Username, standin.Password), commandTimeout).Should(Exit(0))
+			Eventually(Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName), commandTimeout).Should(Exit(0))
+			Eventually(Cf("apps"), commandTimeout).Should(Exit(0))
+		})
+	})
+})
@@ -0,0 +1,11 @@
+package warnings_test
+
+import (
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	gatsHelpers.RunRedactedSpecs(t, "Warnings Suite", "warnings")
+}
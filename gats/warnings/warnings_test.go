@@ -0,0 +1,167 @@
+package warnings_test
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	gatsHelpers "code.cloudfoundry.org/cli-acceptance-tests/gats/helpers"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/standin"
+	"code.cloudfoundry.org/cli-acceptance-tests/gats/strict"
+	. "github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	commandTimeout = 1 * time.Minute
+
+	// deprecatedEndpoint is net.DeprecatedEndpointWarning, which the CLI
+	// never shows.
+	deprecatedEndpoint = "Endpoint deprecated"
+
+	quotaWarning   = "Space gats-space has almost used up its memory quota"
+	summaryWarning = "The space summary endpoint will be removed in a future release"
+)
+
+var (
+	summaryPath = "/v2/spaces/" + standin.SpaceGuid + "/summary"
+	orgsPath    = "/v2/organizations"
+	spacesPath  = "/v2/organizations/" + standin.OrgGuid + "/spaces"
+)
+
+var _ = Describe("X-Cf-Warnings", func() {
+	var (
+		server *standin.Server
+		cfHome string
+
+		restoreCfHome func()
+	)
+
+	output := func(session *Session) string {
+		return string(session.Out.Contents())
+	}
+
+	BeforeEach(func() {
+		server = standin.New()
+
+		cfHome, restoreCfHome = gatsHelpers.UseTempCfHome("gats-warnings")
+
+		Expect(server.SeedCfHome(cfHome)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		restoreCfHome()
+		server.Close()
+	})
+
+	It("prints warnings after the command's output", func() {
+		server.Warn("GET", summaryPath, quotaWarning)
+
+		session := Cf("apps")
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(session).To(Say("No apps found"))
+		Expect(session).To(Say(quotaWarning))
+	})
+
+	It("prints every distinct warning", func() {
+		server.Warn("GET", summaryPath, quotaWarning, summaryWarning)
+
+		session := Cf("apps")
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(output(session)).To(ContainSubstring(quotaWarning))
+		Expect(output(session)).To(ContainSubstring(summaryWarning))
+	})
+
+	It("prints a warning repeated in one response once", func() {
+		server.Warn("GET", summaryPath, quotaWarning, quotaWarning, quotaWarning)
+
+		session := Cf("apps")
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(strings.Count(output(session), quotaWarning)).To(Equal(1))
+	})
+
+	It("prints a warning repeated across a command's requests once", func() {
+		server.Warn("GET", orgsPath, quotaWarning)
+		server.Warn("GET", spacesPath, quotaWarning)
+
+		session := Cf("target", "-o", standin.OrgName, "-s", standin.SpaceName)
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		var paths []string
+		for _, request := range server.Requests() {
+			paths = append(paths, request.Path)
+		}
+		Expect(paths).To(ContainElement(orgsPath))
+		Expect(paths).To(ContainElement(spacesPath))
+		Expect(strings.Count(output(session), quotaWarning)).To(Equal(1))
+	})
+
+	It("doesn't print the deprecated endpoint warning", func() {
+		server.Warn("GET", summaryPath, deprecatedEndpoint, quotaWarning)
+
+		session := Cf("apps")
+		Eventually(session, commandTimeout).Should(Exit(0))
+
+		Expect(output(session)).To(ContainSubstring(quotaWarning))
+		Expect(output(session)).NotTo(ContainSubstring(deprecatedEndpoint))
+	})
+
+	Context("with CF_RAISE_ERROR_ON_WARNINGS set", func() {
+		var originalRaiseError string
+
+		BeforeEach(func() {
+			originalRaiseError = os.Getenv(strict.RaiseErrorEnvVar)
+			os.Setenv(strict.RaiseErrorEnvVar, "true")
+		})
+
+		AfterEach(func() {
+			os.Setenv(strict.RaiseErrorEnvVar, originalRaiseError)
+		})
+
+		It("crashes with the warnings instead of printing them", func() {
+			server.Warn("GET", summaryPath, quotaWarning, summaryWarning)
+
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			warnings, raised := strict.ParseRaisedWarnings(output(session) + string(session.Err.Contents()))
+			Expect(raised).To(BeTrue(), "expected a crash dialog from PrintWarnings in:\n%s", output(session))
+			Expect(warnings).To(Equal([]string{quotaWarning, summaryWarning}))
+		})
+
+		It("raises repeated warnings without de-duplicating them", func() {
+			server.Warn("GET", summaryPath, quotaWarning, quotaWarning)
+
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(1))
+
+			warnings, raised := strict.ParseRaisedWarnings(output(session) + string(session.Err.Contents()))
+			Expect(raised).To(BeTrue())
+			Expect(warnings).To(Equal([]string{quotaWarning, quotaWarning}))
+		})
+
+		It("doesn't raise the deprecated endpoint warning", func() {
+			server.Warn("GET", summaryPath, deprecatedEndpoint)
+
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			_, raised := strict.ParseRaisedWarnings(output(session) + string(session.Err.Contents()))
+			Expect(raised).To(BeFalse())
+		})
+
+		It("succeeds when the Cloud Controller sends no warnings", func() {
+			session := Cf("apps")
+			Eventually(session, commandTimeout).Should(Exit(0))
+
+			Expect(session).To(Say("No apps found"))
+		})
+	})
+})